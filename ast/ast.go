@@ -23,6 +23,7 @@ type Expression interface {
 
 type Program struct {
 	Statements []Statement
+	Ending     []*Comment // After the last statement
 }
 
 func (p *Program) TokenLiteral() string {
@@ -42,8 +43,10 @@ func (p *Program) String() string {
 
 type LetStatement struct {
 	Token token.Token
-	Name  *Identifier
+	Name  Expression // An identifier, or an array or hash pattern destructuring the value
 	Value Expression
+	Doc   string // Text of the /// comments right before it
+	Attached
 }
 
 func (ls *LetStatement) statementNode()       {}
@@ -58,9 +61,32 @@ func (ls *LetStatement) String() string {
 	return out.String()
 }
 
+// Declares a struct type, binding its name to the constructor
+type StructStatement struct {
+	Token  token.Token // The 'struct' token
+	Name   *Identifier
+	Fields []*Identifier
+	Doc    string // Text of the /// comments right before it
+	Attached
+}
+
+func (ss *StructStatement) statementNode()       {}
+func (ss *StructStatement) TokenLiteral() string { return ss.Token.Literal }
+func (ss *StructStatement) String() string {
+	fields := []string{}
+	for _, f := range ss.Fields {
+		fields = append(fields, f.String())
+	}
+	if len(fields) == 0 {
+		return ss.TokenLiteral() + " " + ss.Name.String() + " {}"
+	}
+	return ss.TokenLiteral() + " " + ss.Name.String() + " { " + strings.Join(fields, ", ") + " }"
+}
+
 type ReturnStatement struct {
 	Token    token.Token
 	RetValue Expression
+	Attached
 }
 
 func (rs *ReturnStatement) statementNode()       {}
@@ -72,9 +98,30 @@ func (rs *ReturnStatement) String() string {
 	return out.String()
 }
 
+// Stops the innermost loop, only allowed in loop bodies
+type BreakStatement struct {
+	Token token.Token
+	Attached
+}
+
+func (bs *BreakStatement) statementNode()       {}
+func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BreakStatement) String() string       { return bs.TokenLiteral() }
+
+// Goes to the next iteration of the innermost loop, only allowed in loop bodies
+type ContinueStatement struct {
+	Token token.Token
+	Attached
+}
+
+func (cs *ContinueStatement) statementNode()       {}
+func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ContinueStatement) String() string       { return cs.TokenLiteral() }
+
 type ExpressionStatement struct {
 	Token      token.Token // First token of the expression
 	Expression Expression
+	Attached
 }
 
 func (es *ExpressionStatement) statementNode()       {}
@@ -84,6 +131,8 @@ func (es *ExpressionStatement) String() string       { return es.Expression.Stri
 type BlockStatement struct {
 	Token      token.Token
 	Statements []Statement
+	End        token.Token // Closing brace
+	Ending     []*Comment  // After the last statement
 }
 
 func (bs *BlockStatement) statementNode()       {}
@@ -165,11 +214,16 @@ func (b *BooleanLiteral) expressionNode()      {}
 func (b *BooleanLiteral) TokenLiteral() string { return b.Token.Literal }
 func (b *BooleanLiteral) String() string       { return b.Token.Literal }
 
-type IfExpression struct {
-	Token       token.Token
+// A condition and the block running when it holds
+type IfBranch struct {
 	Condition   Expression
 	Consequence *BlockStatement
-	Alternative *BlockStatement
+}
+
+type IfExpression struct {
+	Token       token.Token
+	Branches    []IfBranch      // The if and its else ifs in order
+	Alternative *BlockStatement // The last else, if any
 }
 
 func (ie *IfExpression) expressionNode()      {}
@@ -177,10 +231,15 @@ func (ie *IfExpression) TokenLiteral() string { return ie.Token.Literal }
 func (ie *IfExpression) String() string {
 	var out bytes.Buffer
 
-	out.WriteString(ie.TokenLiteral() + " ")
-	out.WriteString(ie.Condition.String())
-	out.WriteString(" ")
-	out.WriteString(ie.Consequence.String())
+	for i, branch := range ie.Branches {
+		if i > 0 {
+			out.WriteString(" else ")
+		}
+		out.WriteString(ie.TokenLiteral() + " ")
+		out.WriteString(branch.Condition.String())
+		out.WriteString(" ")
+		out.WriteString(branch.Consequence.String())
+	}
 
 	if ie.Alternative != nil {
 		out.WriteString(" else ")
@@ -190,9 +249,34 @@ func (ie *IfExpression) String() string {
 	return out.String()
 }
 
+type WhileExpression struct {
+	Token     token.Token
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (we *WhileExpression) expressionNode()      {}
+func (we *WhileExpression) TokenLiteral() string { return we.Token.Literal }
+func (we *WhileExpression) String() string {
+	return we.TokenLiteral() + " " + we.Condition.String() + " " + we.Body.String()
+}
+
+type ForExpression struct {
+	Token    token.Token
+	Name     Expression // An identifier, or an array or hash pattern destructuring each element
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fe *ForExpression) expressionNode()      {}
+func (fe *ForExpression) TokenLiteral() string { return fe.Token.Literal }
+func (fe *ForExpression) String() string {
+	return fe.TokenLiteral() + " " + fe.Name.String() + " in " + fe.Iterable.String() + " " + fe.Body.String()
+}
+
 type FunctionLiteral struct {
 	Token      token.Token
-	Parameters []*Identifier
+	Parameters []Expression // Identifiers, array and hash patterns, default parameters, and a last rest element
 	Body       *BlockStatement
 }
 
@@ -241,10 +325,36 @@ func (m *MacroLiteral) String() string {
 	return out.String()
 }
 
+type AstMacroLiteral struct {
+	Token      token.Token
+	Parameters []*Identifier
+	Body       *BlockStatement
+}
+
+func (m *AstMacroLiteral) expressionNode()      {}
+func (m *AstMacroLiteral) TokenLiteral() string { return m.Token.Literal }
+func (m *AstMacroLiteral) String() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range m.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString(m.TokenLiteral())
+	out.WriteString(token.LPAREN)
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(token.RPAREN + " ")
+	out.WriteString(m.Body.String())
+
+	return out.String()
+}
+
 type CallExpression struct {
 	Token     token.Token
 	Function  Expression
-	Arguments []Expression
+	Arguments []Expression // Named arguments go after the positional ones
+	Piped     bool         // Written as first |> f(rest), the first argument went through the pipe
 }
 
 func (ce *CallExpression) expressionNode()      {}
@@ -257,14 +367,37 @@ func (ce *CallExpression) String() string {
 		args = append(args, a.String())
 	}
 
+	if ce.Piped {
+		out.WriteString(token.LPAREN + args[0] + " " + token.PIPE + " ")
+		args = args[1:]
+	}
+
 	out.WriteString(ce.Function.String())
 	out.WriteString(token.LPAREN)
 	out.WriteString(strings.Join(args, ", "))
 	out.WriteString(token.RPAREN)
 
+	if ce.Piped {
+		out.WriteString(token.RPAREN)
+	}
+
 	return out.String()
 }
 
+// The values of all the arguments and the names of the last len(names), which are named
+func (ce *CallExpression) SplitArguments() ([]Expression, []string) {
+	values := []Expression{}
+	names := []string{}
+	for _, arg := range ce.Arguments {
+		if named, ok := arg.(*NamedArgument); ok {
+			names = append(names, named.Name.Value)
+			arg = named.Value
+		}
+		values = append(values, arg)
+	}
+	return values, names
+}
+
 type StringLiteral struct {
 	Token token.Token
 	Value string
@@ -295,6 +428,7 @@ func (a *ExpressionsContainer) String() string {
 
 type ArrayLiteral struct {
 	ExpressionsContainer
+	ItemComments
 }
 
 type TemplateString struct {
@@ -321,9 +455,28 @@ func (i *IndexExpression) String() string {
 	return out.String()
 }
 
+// Either hash.key or, when called, a method on the value
+type MemberExpression struct {
+	Token token.Token // The '.' token
+	Left  Expression
+	Name  *Identifier
+}
+
+func (m *MemberExpression) expressionNode()      {}
+func (m *MemberExpression) TokenLiteral() string { return m.Token.Literal }
+func (m *MemberExpression) String() string {
+	return "(" + m.Left.String() + "." + m.Name.String() + ")"
+}
+
+type HashPair struct {
+	Key   Expression
+	Value Expression
+}
+
 type HashLiteral struct {
 	Token token.Token
-	Pairs map[Expression]Expression
+	Pairs []HashPair // In source order
+	ItemComments
 }
 
 func (h *HashLiteral) expressionNode()      {}
@@ -332,8 +485,8 @@ func (h *HashLiteral) String() string {
 	var out bytes.Buffer
 
 	pairs := []string{}
-	for k, v := range h.Pairs {
-		pairs = append(pairs, k.String()+":"+v.String())
+	for _, pair := range h.Pairs {
+		pairs = append(pairs, pair.Key.String()+":"+pair.Value.String())
 	}
 	out.WriteString("{")
 	out.WriteString(strings.Join(pairs, ", "))
@@ -341,3 +494,77 @@ func (h *HashLiteral) String() string {
 
 	return out.String()
 }
+
+// The remaining elements of an array pattern, as in [head, ...rest]
+type RestElement struct {
+	Token token.Token
+	Name  *Identifier
+}
+
+func (r *RestElement) expressionNode()      {}
+func (r *RestElement) TokenLiteral() string { return r.Token.Literal }
+func (r *RestElement) String() string       { return token.ELLIPSIS + r.Name.String() }
+
+// Parameter taking the value when the call doesn't pass it, as in fn(x, y = 1)
+type DefaultParameter struct {
+	Token     token.Token // The = token
+	Parameter Expression
+	Value     Expression
+}
+
+func (d *DefaultParameter) expressionNode()      {}
+func (d *DefaultParameter) TokenLiteral() string { return d.Token.Literal }
+func (d *DefaultParameter) String() string {
+	return d.Parameter.String() + " " + token.ASSIGN + " " + d.Value.String()
+}
+
+// Argument passed by the name of the parameter, as in f(1, y: 2)
+type NamedArgument struct {
+	Token token.Token // The name token
+	Name  *Identifier
+	Value Expression
+}
+
+func (n *NamedArgument) expressionNode()      {}
+func (n *NamedArgument) TokenLiteral() string { return n.Token.Literal }
+func (n *NamedArgument) String() string {
+	return n.Name.String() + token.COLON + " " + n.Value.String()
+}
+
+// A pattern, the guard it needs if any, and the value of the match when both hold
+type MatchArm struct {
+	Pattern Expression
+	Guard   Expression
+	Value   Expression
+}
+
+type MatchExpression struct {
+	Token token.Token
+	Value Expression
+	Arms  []MatchArm
+}
+
+func (m *MatchExpression) expressionNode()      {}
+func (m *MatchExpression) TokenLiteral() string { return m.Token.Literal }
+func (m *MatchExpression) String() string {
+	var out bytes.Buffer
+
+	arms := []string{}
+	for _, arm := range m.Arms {
+		text := arm.Pattern.String()
+		if arm.Guard != nil {
+			text += " if " + arm.Guard.String()
+		}
+		arms = append(arms, text+" "+token.ARROW+" "+arm.Value.String())
+	}
+
+	out.WriteString(m.TokenLiteral() + " ")
+	out.WriteString(m.Value.String())
+	if len(arms) == 0 {
+		out.WriteString(" {}")
+	} else {
+		out.WriteString(" { " + strings.Join(arms, ", ") + " }")
+	}
+
+	return out.String()
+}
@@ -26,3 +26,23 @@ func TestString(t *testing.T) {
 		t.Errorf("expected %s, got %s", "let a = b;", program.String())
 	}
 }
+
+func TestSplitArguments(t *testing.T) {
+	one := &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "1"}, Value: 1}
+	two := &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "2"}, Value: 2}
+	call := &CallExpression{
+		Function: &Identifier{Token: token.Token{Type: token.IDENT, Literal: "f"}, Value: "f"},
+		Arguments: []Expression{
+			one,
+			&NamedArgument{Name: &Identifier{Token: token.Token{Type: token.IDENT, Literal: "y"}, Value: "y"}, Value: two},
+		},
+	}
+
+	values, names := call.SplitArguments()
+	if len(values) != 2 || values[0] != one || values[1] != two {
+		t.Errorf("expected the values 1 and 2, got %v", values)
+	}
+	if len(names) != 1 || names[0] != "y" {
+		t.Errorf("expected the names [y], got %v", names)
+	}
+}
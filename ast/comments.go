@@ -0,0 +1,150 @@
+package ast
+
+import "monkey/token"
+
+// A comment, doc comments are always kept, the rest only when the lexer returns them
+type Comment struct {
+	Token    token.Token
+	Trailing bool // Whether there is code before it in its line
+}
+
+func (c *Comment) Text() string { return c.Token.Literal }
+
+// Comments around a statement or an item of a literal
+type Attached struct {
+	Leading  []*Comment // In the lines before it
+	Trailing []*Comment // After its code
+}
+
+func (a *Attached) Comments() *Attached { return a }
+
+// Comments between the items of a hash or array literal, nil Items when there are none
+type ItemComments struct {
+	Items  []Attached // Of each item, up to the last one with comments
+	Ending []*Comment // After the last item
+}
+
+// Whether any item or the end of the literal has comments
+func (c *ItemComments) Any() bool {
+	return len(c.Items) > 0 || len(c.Ending) > 0
+}
+
+// The comments of the item i, empty when it has none
+func (c *ItemComments) Item(i int) Attached {
+	if i < len(c.Items) {
+		return c.Items[i]
+	}
+	return Attached{}
+}
+
+// Statements that can have comments attached
+type Commented interface {
+	Statement
+	Comments() *Attached
+}
+
+// Line where the statement starts, 0 for generated ones
+func StatementLine(stmt Statement) int {
+	switch stmt := stmt.(type) {
+	case *LetStatement:
+		return stmt.Token.Line
+	case *StructStatement:
+		return stmt.Token.Line
+	case *ReturnStatement:
+		return stmt.Token.Line
+	case *BreakStatement:
+		return stmt.Token.Line
+	case *ContinueStatement:
+		return stmt.Token.Line
+	case *ExpressionStatement:
+		return stmt.Token.Line
+	}
+	return 0
+}
+
+// Calls f for the node and then its children while f returns true
+func Inspect(node Node, f func(Node) bool) {
+	if node == nil || !f(node) {
+		return
+	}
+
+	switch node := node.(type) {
+	case *Program:
+		for _, stmt := range node.Statements {
+			Inspect(stmt, f)
+		}
+	case *BlockStatement:
+		for _, stmt := range node.Statements {
+			Inspect(stmt, f)
+		}
+	case *ExpressionStatement:
+		Inspect(node.Expression, f)
+	case *ReturnStatement:
+		Inspect(node.RetValue, f)
+	case *LetStatement:
+		Inspect(node.Value, f)
+	case *PrefixExpression:
+		Inspect(node.Right, f)
+	case *InfixExpression:
+		Inspect(node.Left, f)
+		Inspect(node.Right, f)
+	case *IndexExpression:
+		Inspect(node.Left, f)
+		Inspect(node.Index, f)
+	case *MemberExpression:
+		Inspect(node.Left, f)
+	case *IfExpression:
+		for _, branch := range node.Branches {
+			Inspect(branch.Condition, f)
+			Inspect(branch.Consequence, f)
+		}
+		if node.Alternative != nil {
+			Inspect(node.Alternative, f)
+		}
+	case *WhileExpression:
+		Inspect(node.Condition, f)
+		Inspect(node.Body, f)
+	case *ForExpression:
+		Inspect(node.Name, f)
+		Inspect(node.Iterable, f)
+		Inspect(node.Body, f)
+	case *FunctionLiteral:
+		for _, p := range node.Parameters {
+			Inspect(p, f)
+		}
+		Inspect(node.Body, f)
+	case *AstMacroLiteral:
+		Inspect(node.Body, f)
+	case *CallExpression:
+		Inspect(node.Function, f)
+		for _, arg := range node.Arguments {
+			Inspect(arg, f)
+		}
+	case *ArrayLiteral:
+		for _, el := range node.Elements {
+			Inspect(el, f)
+		}
+	case *HashLiteral:
+		for _, pair := range node.Pairs {
+			Inspect(pair.Key, f)
+			Inspect(pair.Value, f)
+		}
+	case *RestElement:
+		Inspect(node.Name, f)
+	case *DefaultParameter:
+		Inspect(node.Parameter, f)
+		Inspect(node.Value, f)
+	case *NamedArgument:
+		Inspect(node.Name, f)
+		Inspect(node.Value, f)
+	case *MatchExpression:
+		Inspect(node.Value, f)
+		for _, arm := range node.Arms {
+			Inspect(arm.Pattern, f)
+			if arm.Guard != nil {
+				Inspect(arm.Guard, f)
+			}
+			Inspect(arm.Value, f)
+		}
+	}
+}
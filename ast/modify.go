@@ -0,0 +1,142 @@
+package ast
+
+type ModifierFunc func(Node) Node
+
+// Walks the tree depth first replacing every node with the modifier result.
+// The given tree is left untouched, the modified nodes are copies.
+func Modify(node Node, modifier ModifierFunc) Node {
+	switch node := node.(type) {
+	case *Program:
+		program := *node
+		program.Statements = modifyStatements(node.Statements, modifier)
+		return modifier(&program)
+	case *ExpressionStatement:
+		stmt := *node
+		stmt.Expression, _ = Modify(node.Expression, modifier).(Expression)
+		return modifier(&stmt)
+	case *BlockStatement:
+		block := *node
+		block.Statements = modifyStatements(node.Statements, modifier)
+		return modifier(&block)
+	case *ReturnStatement:
+		stmt := *node
+		stmt.RetValue, _ = Modify(node.RetValue, modifier).(Expression)
+		return modifier(&stmt)
+	case *LetStatement:
+		stmt := *node
+		stmt.Value, _ = Modify(node.Value, modifier).(Expression)
+		return modifier(&stmt)
+	case *PrefixExpression:
+		exp := *node
+		exp.Right, _ = Modify(node.Right, modifier).(Expression)
+		return modifier(&exp)
+	case *InfixExpression:
+		exp := *node
+		exp.Left, _ = Modify(node.Left, modifier).(Expression)
+		exp.Right, _ = Modify(node.Right, modifier).(Expression)
+		return modifier(&exp)
+	case *IndexExpression:
+		exp := *node
+		exp.Left, _ = Modify(node.Left, modifier).(Expression)
+		exp.Index, _ = Modify(node.Index, modifier).(Expression)
+		return modifier(&exp)
+	case *MemberExpression:
+		exp := *node
+		exp.Left, _ = Modify(node.Left, modifier).(Expression)
+		return modifier(&exp)
+	case *IfExpression:
+		exp := *node
+		exp.Branches = make([]IfBranch, len(node.Branches))
+		for i, branch := range node.Branches {
+			exp.Branches[i].Condition, _ = Modify(branch.Condition, modifier).(Expression)
+			exp.Branches[i].Consequence, _ = Modify(branch.Consequence, modifier).(*BlockStatement)
+		}
+		if node.Alternative != nil {
+			exp.Alternative, _ = Modify(node.Alternative, modifier).(*BlockStatement)
+		}
+		return modifier(&exp)
+	case *WhileExpression:
+		exp := *node
+		exp.Condition, _ = Modify(node.Condition, modifier).(Expression)
+		exp.Body, _ = Modify(node.Body, modifier).(*BlockStatement)
+		return modifier(&exp)
+	case *ForExpression:
+		exp := *node
+		exp.Iterable, _ = Modify(node.Iterable, modifier).(Expression)
+		exp.Body, _ = Modify(node.Body, modifier).(*BlockStatement)
+		return modifier(&exp)
+	case *FunctionLiteral:
+		exp := *node
+		exp.Parameters = make([]Expression, len(node.Parameters))
+		for i, p := range node.Parameters {
+			// Only default values are modified, the rest binds names
+			exp.Parameters[i] = p
+			if _, ok := p.(*DefaultParameter); ok {
+				exp.Parameters[i], _ = Modify(p, modifier).(Expression)
+			}
+		}
+		exp.Body, _ = Modify(node.Body, modifier).(*BlockStatement)
+		return modifier(&exp)
+	case *DefaultParameter:
+		exp := *node
+		exp.Value, _ = Modify(node.Value, modifier).(Expression)
+		return modifier(&exp)
+	case *NamedArgument:
+		exp := *node
+		exp.Value, _ = Modify(node.Value, modifier).(Expression)
+		return modifier(&exp)
+	case *AstMacroLiteral:
+		exp := *node
+		exp.Body, _ = Modify(node.Body, modifier).(*BlockStatement)
+		return modifier(&exp)
+	case *CallExpression:
+		exp := *node
+		exp.Function, _ = Modify(node.Function, modifier).(Expression)
+		exp.Arguments = modifyExpressions(node.Arguments, modifier)
+		return modifier(&exp)
+	case *ArrayLiteral:
+		exp := *node
+		exp.Elements = modifyExpressions(node.Elements, modifier)
+		return modifier(&exp)
+	case *HashLiteral:
+		exp := *node
+		exp.Pairs = make([]HashPair, len(node.Pairs))
+		for i, pair := range node.Pairs {
+			key, _ := Modify(pair.Key, modifier).(Expression)
+			value, _ := Modify(pair.Value, modifier).(Expression)
+			exp.Pairs[i] = HashPair{Key: key, Value: value}
+		}
+		return modifier(&exp)
+	case *MatchExpression:
+		// Patterns only bind names, like parameters they are left as they are
+		exp := *node
+		exp.Value, _ = Modify(node.Value, modifier).(Expression)
+		exp.Arms = make([]MatchArm, len(node.Arms))
+		for i, arm := range node.Arms {
+			exp.Arms[i].Pattern = arm.Pattern
+			if arm.Guard != nil {
+				exp.Arms[i].Guard, _ = Modify(arm.Guard, modifier).(Expression)
+			}
+			exp.Arms[i].Value, _ = Modify(arm.Value, modifier).(Expression)
+		}
+		return modifier(&exp)
+	}
+
+	return modifier(node)
+}
+
+func modifyStatements(statements []Statement, modifier ModifierFunc) []Statement {
+	modified := make([]Statement, len(statements))
+	for i, stmt := range statements {
+		modified[i], _ = Modify(stmt, modifier).(Statement)
+	}
+	return modified
+}
+
+func modifyExpressions(expressions []Expression, modifier ModifierFunc) []Expression {
+	modified := make([]Expression, len(expressions))
+	for i, exp := range expressions {
+		modified[i], _ = Modify(exp, modifier).(Expression)
+	}
+	return modified
+}
@@ -0,0 +1,84 @@
+package evaluator
+
+import (
+	"monkey/ast"
+	"monkey/object"
+)
+
+// How the parameters of a function take their arguments
+func Signature(params []ast.Expression) []object.Parameter {
+	signature := []object.Parameter{}
+	for _, p := range params {
+		param := object.Parameter{Source: p.String()}
+		switch p := p.(type) {
+		case *ast.Identifier:
+			param.Name = p.Value
+		case *ast.DefaultParameter:
+			param.Default = true
+			if ident, ok := p.Parameter.(*ast.Identifier); ok {
+				param.Name = ident.Value
+			}
+		case *ast.RestElement:
+			param.Rest = true
+		}
+		signature = append(signature, param)
+	}
+	return signature
+}
+
+// Puts the arguments, the last len(names) of them named, in the order of the parameters.
+// Parameters left to their default get nil and a rest parameter the remaining positional ones
+func arrange(fn object.Object, params []object.Parameter, args []object.Object, names []string) ([]object.Object, object.Object) {
+	positional := args[:len(args)-len(names)]
+	arranged := make([]object.Object, len(params))
+
+	fixed := len(params)
+	if fixed > 0 && params[fixed-1].Rest {
+		fixed--
+		remaining := []object.Object{}
+		if len(positional) > fixed {
+			remaining = append(remaining, positional[fixed:]...)
+		}
+		arranged[fixed] = &object.Array{Elements: remaining}
+	} else if len(positional) > fixed {
+		return nil, newError("%s takes at most %d arguments, got %d", callee(fn), fixed, len(positional))
+	}
+	copy(arranged[:fixed], positional)
+
+	for i, name := range names {
+		found := false
+		for j, p := range params[:fixed] {
+			if p.Name != name {
+				continue
+			}
+			if arranged[j] != nil {
+				return nil, newError("%s got %s more than once", callee(fn), name)
+			}
+			arranged[j], found = args[len(positional)+i], true
+			break
+		}
+		if !found {
+			return nil, newError("%s has no parameter %s", callee(fn), name)
+		}
+	}
+
+	missing := 0
+	for j, p := range params[:fixed] {
+		if arranged[j] == nil && !p.Default {
+			missing++
+		}
+	}
+	if missing > 0 {
+		return nil, newError("%s is missing %d parameters", callee(fn), missing)
+	}
+
+	return arranged, nil
+}
+
+// How the errors of arranging the arguments name what is called
+func callee(fn object.Object) string {
+	if st, ok := fn.(*object.StructType); ok {
+		return "struct " + st.Name
+	}
+	return "function " + fn.Inspect()
+}
@@ -3,6 +3,7 @@ package evaluator
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"monkey/ast"
 	"monkey/lexer"
 	"monkey/object"
@@ -18,13 +19,16 @@ var (
 	TRUE  = &object.Boolean{Value: true}
 	FALSE = &object.Boolean{Value: false}
 	NULL  = &object.Null{}
+
+	BREAK    = &object.Break{}
+	CONTINUE = &object.Continue{}
 )
 
 func buildTemplateString(node *ast.TemplateString, env *object.Environment) object.Object {
 	var out bytes.Buffer
 	for _, e := range node.Elements {
 		val := Eval(e, env)
-		if isError(val) {
+		if isAbrupt(val) {
 			return val
 		}
 		out.WriteString(val.Inspect())
@@ -32,12 +36,13 @@ func buildTemplateString(node *ast.TemplateString, env *object.Environment) obje
 	return &object.String{Value: out.String()}
 }
 
-func buildObjects(expressions []ast.Expression, env *object.Environment) ([]object.Object, *object.Error) {
+// Evaluates the expressions, stopping at the first error, break, continue or return
+func buildObjects(expressions []ast.Expression, env *object.Environment) ([]object.Object, object.Object) {
 	objs := []object.Object{}
 	for _, exp := range expressions {
 		val := Eval(exp, env)
-		if isError(val) {
-			return objs, val.(*object.Error)
+		if isAbrupt(val) {
+			return objs, val
 		}
 		objs = append(objs, val)
 	}
@@ -53,10 +58,10 @@ func buildArray(node *ast.ArrayLiteral, env *object.Environment) object.Object {
 }
 
 func buildHash(node *ast.HashLiteral, env *object.Environment) object.Object {
-	pairs := map[object.HashKey]object.HashPair{}
-	for k, v := range node.Pairs {
-		key := Eval(k, env)
-		if isError(key) {
+	hash := object.NewHash()
+	for _, pair := range node.Pairs {
+		key := Eval(pair.Key, env)
+		if isAbrupt(key) {
 			return key
 		}
 
@@ -65,180 +70,292 @@ func buildHash(node *ast.HashLiteral, env *object.Environment) object.Object {
 			return newError("%T=(%v) not yet implemented as hash key!", key, key)
 		}
 
-		val := Eval(v, env)
-		if isError(val) {
+		val := Eval(pair.Value, env)
+		if isAbrupt(val) {
 			return val
 		}
 
-		pairs[hashKey.HashKey()] = object.HashPair{Key: key, Value: val}
+		hash.Set(hashKey.HashKey(), object.HashPair{Key: key, Value: val})
 	}
-	return &object.Hash{Pairs: pairs}
+	return hash
 }
 
 func buildIndex(node *ast.IndexExpression, env *object.Environment) object.Object {
 	left := Eval(node.Left, env)
-	if isError(left) {
+	if isAbrupt(left) {
 		return left
 	}
 
-	if arr, ok := left.(*object.Array); ok {
-		right := Eval(node.Index, env)
-		if isError(right) {
-			return right
-		}
-		if index, ok := right.(*object.Integer); ok {
+	if left.Type() != object.ARRAY && left.Type() != object.HASH {
+		return newError("indexing not supported for %s yet", left.Type())
+	}
+
+	index := Eval(node.Index, env)
+	if isAbrupt(index) {
+		return index
+	}
+
+	return Index(left, index)
+}
+
+func Index(left object.Object, index object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Array:
+		if index, ok := index.(*object.Integer); ok {
 			idx := index.Value
-			if idx < 0 || idx >= int64(len(arr.Elements)) {
+			if idx < 0 || idx >= int64(len(left.Elements)) {
 				return NULL
 			}
-			return arr.Elements[idx]
-		}
-		return newError("indexing by %s is not yet supported", right.Type())
-	} else if hash, ok := left.(*object.Hash); ok {
-		right := Eval(node.Index, env)
-		if isError(right) {
-			return right
+			return left.Elements[idx]
 		}
-		if index, ok := right.(object.Hashable); ok {
-			if val, ok := hash.Pairs[index.HashKey()]; ok {
+		return newError("indexing by %s is not yet supported", index.Type())
+	case *object.Hash:
+		if index, ok := index.(object.Hashable); ok {
+			if val, ok := left.Pairs[index.HashKey()]; ok {
 				return val.Value
 			}
 			return NULL
 		}
-		return newError("indexing by %s is not yet supported", right.Type())
+		return newError("indexing by %s is not yet supported", index.Type())
 	}
 
 	return newError("indexing not supported for %s yet", left.Type())
-
 }
 
 func buildCall(node *ast.CallExpression, env *object.Environment) object.Object {
+	if member, ok := node.Function.(*ast.MemberExpression); ok {
+		return buildMethodCall(node, member, env)
+	}
+
 	caller := Eval(node.Function, env)
-	if isError(caller) {
+	if isAbrupt(caller) {
 		return caller
 	}
 
+	switch caller.(type) {
+	case *object.Macro, *object.CompiledMacro:
+		if len(node.Arguments) != 1 {
+			return newError("wrong number of arguments. got=%d, want=1 string template", len(node.Arguments))
+		}
+	case *object.AstMacro:
+		return newError("macro %s is expanded before evaluation, define it with a top level let", node.Function.String())
+	}
+
+	args, names, err := buildArguments(node, env)
+	if err != nil {
+		return err
+	}
+	return CallNamed(caller, args, names)
+}
+
+// Evaluates the arguments of a call and collects the names of the named ones
+func buildArguments(call *ast.CallExpression, env *object.Environment) ([]object.Object, []string, object.Object) {
+	values, names := call.SplitArguments()
+	args, err := buildObjects(values, env)
+	return args, names, err
+}
+
+// Like Call with the last len(names) arguments passed by name
+func CallNamed(caller object.Object, args []object.Object, names []string) object.Object {
+	switch fn := caller.(type) {
+	case *object.Function:
+		arranged, err := arrange(fn, Signature(fn.Parameters), args, names)
+		if err != nil {
+			return err
+		}
+		return applyFunction(fn, arranged)
+	case *object.CompiledFunction:
+		arranged, err := arrange(fn, fn.Parameters, args, names)
+		if err != nil {
+			return err
+		}
+		return fn.Fn(arranged...)
+	case *object.StructType:
+		values, err := arrange(fn, fn.Parameters(), args, names)
+		if err != nil {
+			return err
+		}
+		return &object.Struct{Of: fn, Values: values}
+	}
+
+	if len(names) > 0 {
+		return newError("%s doesn't take named arguments", caller.Inspect())
+	}
+	return Call(caller, args...)
+}
+
+// Calls any callable with the arguments already evaluated
+func Call(caller object.Object, args ...object.Object) object.Object {
 	switch fn := caller.(type) {
 	case *object.Builtin:
-		args := []object.Object{}
-		for _, a := range node.Arguments {
-			arg := Eval(a, env)
-			if isError(arg) {
-				return arg
-			}
-			args = append(args, arg)
-		}
 		return fn.Fn(args...)
-	case *object.Function:
-		if len(node.Arguments) < len(fn.Parameters) {
-			return newError("function %s is missing %d parameters", fn.Inspect(), len(fn.Parameters)-len(node.Arguments))
+	case *object.Function, *object.CompiledFunction, *object.StructType:
+		return CallNamed(fn, args, nil)
+	case *object.Macro:
+		if len(args) != 1 {
+			return newError("wrong number of arguments. got=%d, want=1 string template", len(args))
 		}
 
-		// TODO: Understand this fucking recursion
-		fnEnv := fn.Env.SmartCopy()
-		for i, p := range fn.Parameters {
-			arg := Eval(node.Arguments[i], env)
-			if isError(arg) {
-				return arg
-			}
-			fnEnv.Set(p.Value, arg)
+		mEnv := fn.Env.SmartCopy()
+		for i, text := range matchMacro(len(fn.Parameters), fn.Patterns, args[0]) {
+			mEnv.Set(fn.Parameters[i].Value, text)
 		}
 
-		ret := Eval(fn.Body, fnEnv)
+		ret := Eval(fn.Body, mEnv)
 		if ret, ok := ret.(*object.Return); ok {
 			return ret.Value
 		}
 		return ret
-	case *object.Macro:
-		if len(node.Arguments) != 1 {
-			return newError("wrong number of arguments. got=%d, want=1 string template", len(node.Arguments))
+	case *object.CompiledMacro:
+		if len(args) != 1 {
+			return newError("wrong number of arguments. got=%d, want=1 string template", len(args))
+		}
+		matches := matchMacro(len(fn.Parameters), fn.Patterns, args[0])
+		for len(matches) < len(fn.Parameters) {
+			matches = append(matches, NULL)
 		}
+		return fn.Fn(matches...)
+	}
 
-		mEnv := fn.Env.SmartCopy()
-		arg := Eval(node.Arguments[0], env)
-		if isError(arg) {
-			return arg
+	return newError("%s callable not supported yet", caller.Type())
+}
+
+func applyFunction(fn *object.Function, args []object.Object) object.Object {
+	// TODO: Understand this fucking recursion
+	fnEnv := fn.Env.SmartCopy()
+	for i, p := range fn.Parameters {
+		value := args[i]
+		if def, ok := p.(*ast.DefaultParameter); ok {
+			p = def.Parameter
+			if value == nil {
+				// Evaluated on each call, it can use the parameters before it
+				value = Eval(def.Value, fnEnv)
+				if isError(value) {
+					return value
+				}
+			}
+		}
+		if rest, ok := p.(*ast.RestElement); ok {
+			p = rest.Name
 		}
 
-		if input, ok := arg.(*object.String); ok {
-			for i := 0; i < len(fn.Parameters); i++ {
-				variable := fn.Parameters[i].Value
-				pattern := fn.Patterns[i]
-				text := &object.String{Value: ""}
-				temp := &object.String{Value: ""}
+		if err := destructure(p, value, fnEnv); isError(err) {
+			return err
+		}
+	}
 
-				var j int
-				var c rune
+	ret := Eval(fn.Body, fnEnv)
+	if ret, ok := ret.(*object.Return); ok {
+		return ret.Value
+	}
+	return ret
+}
 
-				for j, c = range input.Value {
-					char := string(c)
+// Splits the input between the macro parameters, each one taking the text its pattern matches
+func matchMacro(params int, patterns []object.Object, arg object.Object) []object.Object {
+	input, ok := arg.(*object.String)
+	if !ok {
+		return nil
+	}
 
-					switch pat := pattern.(type) {
-					case *object.Builtin:
-						temp.Value = text.Value + char
+	rest := input.Value
+	matches := []object.Object{}
 
-						if ret := pat.Fn(temp); ret.Type() != object.ERROR {
-							text.Value = ret.Inspect()
-							continue
-						}
-					case *object.String:
-						temp.Value += char
+	for i := 0; i < params; i++ {
+		pattern := patterns[i]
+		text := &object.String{Value: ""}
+		temp := &object.String{Value: ""}
 
-						if pat.Value != temp.Value {
-							continue
-						}
+		var j int
+		var c rune
 
-						text.Value = temp.Value
-						j++
-					}
-					break
+		for j, c = range rest {
+			char := string(c)
+
+			switch pat := pattern.(type) {
+			case *object.Builtin:
+				temp.Value = text.Value + char
+
+				if ret := pat.Fn(temp); ret.Type() != object.ERROR {
+					text.Value = ret.Inspect()
+					continue
 				}
+			case *object.String:
+				temp.Value += char
 
-				input.Value = input.Value[j:]
-				mEnv.Set(variable, text)
+				if pat.Value != temp.Value {
+					continue
+				}
+
+				text.Value = temp.Value
+				j++
 			}
+			break
 		}
 
-		ret := Eval(fn.Body, mEnv)
-		if ret, ok := ret.(*object.Return); ok {
-			return ret.Value
-		}
-		return ret
+		rest = rest[j:]
+		matches = append(matches, text)
 	}
 
-	return newError("%s callable not supported yet", caller.Type())
+	return matches
 }
 
 func buildIf(node *ast.IfExpression, env *object.Environment) object.Object {
-	cond := Eval(node.Condition, env)
-	if isError(cond) {
-		return cond
-	}
-
-	switch cond := cond.(type) {
-	case *object.Boolean:
-		if cond == TRUE {
-			return Eval(node.Consequence, env)
-		} else if node.Alternative != nil {
-			return Eval(node.Alternative, env)
+	for _, branch := range node.Branches {
+		cond := Eval(branch.Condition, env)
+		if isAbrupt(cond) {
+			return cond
 		}
-	case *object.Integer:
-		if cond.Value > 0 {
-			return Eval(node.Consequence, env)
+		if IsTruthy(cond) {
+			return buildBranch(branch.Consequence, env)
 		}
-		return Eval(node.Alternative, env)
+	}
+
+	if node.Alternative != nil {
+		return buildBranch(node.Alternative, env)
 	}
 	return NULL
 }
 
+// Empty blocks and blocks ending in a let are null like a missing branch
+func buildBranch(block *ast.BlockStatement, env *object.Environment) object.Object {
+	result := Eval(block, env)
+	if result == nil {
+		return NULL
+	}
+	return result
+}
+
 func buildInfix(node *ast.InfixExpression, env *object.Environment) object.Object {
 	left := Eval(node.Left, env)
-	if isError(left) {
+	if isAbrupt(left) {
+		return left
+	}
+
+	// && and || only evaluate the right side when the left one doesn't decide
+	if node.Operator == token.AND && !IsTruthy(left) || node.Operator == token.OR && IsTruthy(left) {
 		return left
 	}
 
 	right := Eval(node.Right, env)
-	if isError(right) {
+	if isAbrupt(right) {
+		return right
+	}
+
+	return Infix(node.Operator, left, right)
+}
+
+func Infix(operator string, left object.Object, right object.Object) object.Object {
+	switch operator {
+	case token.AND:
+		if !IsTruthy(left) {
+			return left
+		}
+		return right
+	case token.OR:
+		if IsTruthy(left) {
+			return left
+		}
 		return right
 	}
 
@@ -246,7 +363,7 @@ func buildInfix(node *ast.InfixExpression, env *object.Environment) object.Objec
 		left := left.(*object.Integer)
 		right := right.(*object.Integer)
 
-		switch node.Operator {
+		switch operator {
 		case token.PLUS:
 			return &object.Integer{Value: left.Value + right.Value}
 		case token.ASTERISK:
@@ -257,6 +374,10 @@ func buildInfix(node *ast.InfixExpression, env *object.Environment) object.Objec
 			return &object.Integer{Value: left.Value - right.Value}
 		case token.SLASH:
 			return &object.Integer{Value: left.Value / right.Value}
+		case token.BIT_AND:
+			return &object.Integer{Value: left.Value & right.Value}
+		case token.BIT_OR:
+			return &object.Integer{Value: left.Value | right.Value}
 		case token.GT:
 			if left.Value > right.Value {
 				return TRUE
@@ -292,7 +413,7 @@ func buildInfix(node *ast.InfixExpression, env *object.Environment) object.Objec
 		left := left.(*object.Boolean)
 		right := right.(*object.Boolean)
 
-		switch node.Operator {
+		switch operator {
 		case token.EQ:
 			if left.Value == right.Value {
 				return TRUE
@@ -303,12 +424,12 @@ func buildInfix(node *ast.InfixExpression, env *object.Environment) object.Objec
 				return TRUE
 			}
 			return FALSE
-		case token.AND:
+		case token.BIT_AND:
 			if left.Value && right.Value {
 				return TRUE
 			}
 			return FALSE
-		case token.OR:
+		case token.BIT_OR:
 			if left.Value || right.Value {
 				return TRUE
 			}
@@ -318,7 +439,7 @@ func buildInfix(node *ast.InfixExpression, env *object.Environment) object.Objec
 		left := left.(*object.String)
 		right := right.(*object.String)
 
-		switch node.Operator {
+		switch operator {
 		case token.PLUS:
 			return &object.String{Value: left.Value + right.Value}
 		case token.MINUS:
@@ -338,7 +459,7 @@ func buildInfix(node *ast.InfixExpression, env *object.Environment) object.Objec
 		left := left.(*object.Integer)
 		right := right.(*object.String)
 
-		switch node.Operator {
+		switch operator {
 		case token.PLUS:
 			return &object.String{Value: fmt.Sprintf("%d%s", left.Value, right.Value)}
 		case token.ASTERISK:
@@ -348,14 +469,27 @@ func buildInfix(node *ast.InfixExpression, env *object.Environment) object.Objec
 		left := left.(*object.String)
 		right := right.(*object.Integer)
 
-		switch node.Operator {
+		switch operator {
 		case token.PLUS:
 			return &object.String{Value: fmt.Sprintf("%s%d", left.Value, right.Value)}
 		case token.ASTERISK:
 			return &object.String{Value: strings.Repeat(left.Value, int(right.Value))}
 		}
+	} else if left.Type() == object.STRUCT && right.Type() == object.STRUCT {
+		switch operator {
+		case token.EQ:
+			if structsEqual(left.(*object.Struct), right.(*object.Struct)) {
+				return TRUE
+			}
+			return FALSE
+		case token.NE:
+			if !structsEqual(left.(*object.Struct), right.(*object.Struct)) {
+				return TRUE
+			}
+			return FALSE
+		}
 	} else {
-		switch node.Operator {
+		switch operator {
 		case token.EQ:
 			if left == right {
 				return TRUE
@@ -369,31 +503,25 @@ func buildInfix(node *ast.InfixExpression, env *object.Environment) object.Objec
 		}
 	}
 
-	return newError("Operation %s between %s and %s not implemented!", node.Operator, left.Type(), right.Type())
+	return newError("Operation %s between %s and %s not implemented!", operator, left.Type(), right.Type())
 }
 
 func buildPrefix(node *ast.PrefixExpression, env *object.Environment) object.Object {
 	right := Eval(node.Right, env)
-	if isError(right) {
+	if isAbrupt(right) {
 		return right
 	}
 
-	switch node.Operator {
+	return Prefix(node.Operator, right)
+}
+
+func Prefix(operator string, right object.Object) object.Object {
+	switch operator {
 	case token.BANG:
-		switch right := right.(type) {
-		case *object.Integer:
-			if right.Value > 0 {
-				return FALSE
-			}
-			return TRUE
-		case *object.Boolean:
-			if right == TRUE {
-				return FALSE
-			}
-			return TRUE
-		default:
-			return newError("Not implemented %s for %s", token.BANG, right.Type())
+		if IsTruthy(right) {
+			return FALSE
 		}
+		return TRUE
 	case token.MINUS:
 		switch right := right.(type) {
 		case *object.Integer:
@@ -402,12 +530,17 @@ func buildPrefix(node *ast.PrefixExpression, env *object.Environment) object.Obj
 		return newError("Not implemented %s for %s", token.MINUS, right.Type())
 	}
 
-	fmt.Printf("Not implemented operator %s!\n", node.Operator)
-	return NULL
+	return newError("unknown operator: %s", operator)
+}
+
+// The names Builtin resolves
+var BUILTINS = []string{
+	"args", "assert", "echo", "emit", "eval", "exit", "head", "ident", "idents", "int", "last", "len", "null", "push", "range", "raw", "read", "space", "string", "tail", "type",
 }
 
-func buildBuiltin(node *ast.Identifier, env *object.Environment) object.Object {
-	switch node.Value {
+// Returns the builtin with the given name or nil, env being where eval runs the code
+func Builtin(name string, env *object.Environment) object.Object {
+	switch name {
 	case "null":
 		return NULL
 	case "int":
@@ -583,17 +716,41 @@ func buildBuiltin(node *ast.Identifier, env *object.Environment) object.Object {
 				return &object.String{Value: strings.Join(all, "")}
 			},
 		}
-	case "echo":
+	case "range":
 		return &object.Builtin{
 			Fn: func(args ...object.Object) object.Object {
-				all := []string{}
+				if len(args) != 1 && len(args) != 2 {
+					return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+				}
+
+				bounds := []int64{0}
 				for _, arg := range args {
-					all = append(all, arg.Inspect())
+					integer, ok := arg.(*object.Integer)
+					if !ok {
+						return newError("argument to `range` not supported, got %s", arg.Type())
+					}
+					bounds = append(bounds, integer.Value)
 				}
-				fmt.Println(strings.Join(all, ""))
-				return NULL
+				start, end := bounds[len(bounds)-2], bounds[len(bounds)-1]
+
+				elements := []object.Object{}
+				for i := start; i < end; i++ {
+					elements = append(elements, &object.Integer{Value: i})
+				}
+				return &object.Array{Elements: elements}
 			},
 		}
+	case "type":
+		return &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return newError("wrong number of arguments. got=%d, want=1", len(args))
+				}
+				return &object.String{Value: TypeName(args[0])}
+			},
+		}
+	case "echo":
+		return Echo(os.Stdout)
 	case "raw":
 		return &object.Builtin{
 			Fn: func(args ...object.Object) object.Object {
@@ -622,6 +779,57 @@ func buildBuiltin(node *ast.Identifier, env *object.Environment) object.Object {
 				return newError("argument to `read` not supported yet, got %s", args[0].Type())
 			},
 		}
+	case "args":
+		return &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				return &object.Array{Elements: []object.Object{}}
+			},
+		}
+	case "exit":
+		return &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if len(args) > 1 {
+					return newError("wrong number of arguments. got=%d, want=0 or 1", len(args))
+				}
+
+				code := int64(0)
+				if len(args) == 1 {
+					integer, ok := args[0].(*object.Integer)
+					if !ok {
+						return newError("argument to `exit` must be INTEGER, got %s", args[0].Type())
+					}
+					code = integer.Value
+				}
+				return &object.Error{Message: fmt.Sprintf("exit status %d", code), Exit: true, Code: int(code)}
+			},
+		}
+	case "assert":
+		return &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if len(args) == 0 {
+					return newError("wrong number of arguments. got=0, want=1 or more")
+				}
+
+				if IsTruthy(args[0]) {
+					return NULL
+				}
+
+				message := []string{}
+				for _, arg := range args[1:] {
+					message = append(message, arg.Inspect())
+				}
+				if len(message) == 0 {
+					return newError("assertion failed")
+				}
+				return newError("assertion failed: %s", strings.Join(message, ""))
+			},
+		}
+	case "emit":
+		return &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				return newError("emit is only available when generating files with `monkey gen`")
+			},
+		}
 	case "eval":
 		return &object.Builtin{
 			Fn: func(args ...object.Object) object.Object {
@@ -631,7 +839,17 @@ func buildBuiltin(node *ast.Identifier, env *object.Environment) object.Object {
 				if text, ok := args[0].(*object.String); ok {
 					lexer := lexer.New(text.Value)
 					parser := parser.New(lexer)
-					return Eval(parser.ParseProgram(), env)
+					program := parser.ParseProgram()
+					if len(parser.Errors()) > 0 {
+						return newError("%s", strings.Join(parser.Errors(), "\n"))
+					}
+
+					DefineMacros(program, env)
+					expanded, err := ExpandMacros(program, env)
+					if err != nil {
+						return err
+					}
+					return Eval(expanded, env)
 				}
 				return newError("argument to `eval` not supported yet, got %s", args[0].Type())
 			},
@@ -640,10 +858,44 @@ func buildBuiltin(node *ast.Identifier, env *object.Environment) object.Object {
 	return nil
 }
 
+// The echo builtin writing to out
+func Echo(out io.Writer) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			all := []string{}
+			for _, arg := range args {
+				all = append(all, arg.Inspect())
+			}
+			fmt.Fprintln(out, strings.Join(all, ""))
+			return NULL
+		},
+	}
+}
+
 func newError(format string, a ...any) *object.Error {
 	return &object.Error{Message: fmt.Sprintf(format, a...)}
 }
 
+// Whether if, !, && and || take the value as true: null, false, 0 and empty
+// strings, arrays and hashes are false, anything else is true
+func IsTruthy(obj object.Object) bool {
+	switch obj := obj.(type) {
+	case nil, *object.Null:
+		return false
+	case *object.Boolean:
+		return obj.Value
+	case *object.Integer:
+		return obj.Value != 0
+	case *object.String:
+		return obj.Value != ""
+	case *object.Array:
+		return len(obj.Elements) > 0
+	case *object.Hash:
+		return len(obj.Pairs) > 0
+	}
+	return true
+}
+
 func isError(obj object.Object) bool {
 	if obj != nil {
 		return obj.Type() == object.ERROR
@@ -651,6 +903,16 @@ func isError(obj object.Object) bool {
 	return false
 }
 
+// Whether the value ends the expression it is in: an error, or a break, continue or return
+// of a block inside of it, which goes on to its loop or function like in the transpilers
+func isAbrupt(obj object.Object) bool {
+	switch obj.(type) {
+	case *object.Error, *object.Break, *object.Continue, *object.Return:
+		return true
+	}
+	return false
+}
+
 func Eval(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
 	case *ast.Program:
@@ -670,13 +932,21 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		for _, stmt := range node.Statements {
 			result = Eval(stmt, env)
 			switch result.(type) {
-			case *object.Return, *object.Error:
+			case *object.Return, *object.Error, *object.Break, *object.Continue:
 				return result
 			}
 		}
 		return result
 	case *ast.ExpressionStatement:
 		return Eval(node.Expression, env)
+	case *ast.BreakStatement:
+		return BREAK
+	case *ast.ContinueStatement:
+		return CONTINUE
+	case *ast.WhileExpression:
+		return evalWhile(node, env)
+	case *ast.ForExpression:
+		return evalFor(node, env)
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
 	case *ast.BooleanLiteral:
@@ -690,23 +960,35 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return buildInfix(node, env)
 	case *ast.IfExpression:
 		return buildIf(node, env)
+	case *ast.MatchExpression:
+		return buildMatch(node, env)
+	case *ast.RestElement:
+		return newError("%s is only allowed in patterns", node.String())
 	case *ast.ReturnStatement:
 		ret := Eval(node.RetValue, env)
-		if isError(ret) {
+		if isAbrupt(ret) {
 			return ret
 		}
 		return &object.Return{Value: ret}
+	case *ast.NamedArgument:
+		return newError("%s is only allowed in calls", node.String())
 	case *ast.LetStatement:
 		value := Eval(node.Value, env)
-		if isError(value) {
+		if isAbrupt(value) {
 			return value
 		}
-		return env.Set(node.Name.Value, value)
+		return destructure(node.Name, value, env)
+	case *ast.StructStatement:
+		fields := []string{}
+		for _, f := range node.Fields {
+			fields = append(fields, f.Value)
+		}
+		return env.Set(node.Name.Value, &object.StructType{Name: node.Name.Value, Fields: fields})
 	case *ast.Identifier:
 		if value, ok := env.Get(node.Value); ok {
 			return value
 		}
-		if obj := buildBuiltin(node, env); obj != nil {
+		if obj := Builtin(node.Value, env); obj != nil {
 			return obj
 		}
 		return NULL
@@ -718,7 +1000,12 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			return err
 		}
 		return &object.Macro{Parameters: node.Parameters, Patterns: patterns, Body: node.Body, Env: env}
+	case *ast.AstMacroLiteral:
+		return &object.AstMacro{Parameters: node.Parameters, Body: node.Body, Env: env}
 	case *ast.CallExpression:
+		if isCallTo(node, "quote") {
+			return buildQuote(node, env)
+		}
 		return buildCall(node, env)
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
@@ -728,6 +1015,8 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return buildTemplateString(node, env)
 	case *ast.IndexExpression:
 		return buildIndex(node, env)
+	case *ast.MemberExpression:
+		return buildMember(node, env)
 	case *ast.HashLiteral:
 		return buildHash(node, env)
 	}
@@ -1,6 +1,7 @@
 package evaluator
 
 import (
+	"bytes"
 	"monkey/lexer"
 	"monkey/object"
 	"monkey/parser"
@@ -87,6 +88,37 @@ func TestHashLiterals(t *testing.T) {
 	}
 }
 
+func TestHashOrder(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"b": 1, "a": 2, 3: 3, true: 4}`, "{b:1, a:2, 3:3, true:4}"},
+		{`{"b": 1, "a": 2, "b": 3}`, "{b:3, a:2}"},
+		{`{}`, "{}"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong inspect for %q. expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestHashEvaluationOrder(t *testing.T) {
+	var out bytes.Buffer
+	env := object.NewEnvironment()
+	env.Set("echo", Echo(&out))
+
+	program := parser.New(lexer.New(`{3: echo("a"), 1: echo("b"), 2: echo("c"), 0: echo("d")}`)).ParseProgram()
+	Eval(program, env)
+
+	if out.String() != "a\nb\nc\nd\n" {
+		t.Errorf("wrong evaluation order. got=%q", out.String())
+	}
+}
+
 func TestArrayLiterals(t *testing.T) {
 	input := "[1, 2 * 2, 3 + 3]"
 	evaluated := testEval(input)
@@ -135,6 +167,7 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`len("")`, 0},
 		{`len("four")`, 4},
 		{`len("hello world")`, 11},
+		{`len("héllo")`, 6},
 		{`len(1)`, "argument to `len` not supported, got INTEGER"},
 		{`len("one", "two")`, "wrong number of arguments. got=2, want=1"},
 		{`head([1, 2])`, 1},
@@ -171,6 +204,16 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`tail("")`, nil},
 		{`last("")`, nil},
 		{`raw("hi\n")`, `"hi\n"`},
+		{`len(args())`, 0},
+		{`assert(1 < 2, "unused")`, nil},
+		{`assert(false)`, "assertion failed"},
+		{`assert(0, "zero is ", false)`, "assertion failed: zero is false"},
+		{`assert("x")`, nil},
+		{`assert([1], "unused")`, nil},
+		{`assert(-1)`, nil},
+		{`assert([], "empty")`, "assertion failed: empty"},
+		{`exit(1, 2)`, "wrong number of arguments. got=2, want=0 or 1"},
+		{`exit("1")`, "argument to `exit` must be INTEGER, got STRING"},
 	}
 
 	for _, tt := range tests {
@@ -195,6 +238,37 @@ func TestBuiltinFunctions(t *testing.T) {
 	}
 }
 
+func TestBuiltinNames(t *testing.T) {
+	for _, name := range BUILTINS {
+		if Builtin(name, object.NewEnvironment()) == nil {
+			t.Errorf("builtin %s not found", name)
+		}
+	}
+}
+
+func TestExit(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{`exit()`, 0},
+		{`exit(3); 5`, 3},
+		{`let f = fn() { if (true) { exit(4) }; 1 }; [f(), 2]`, 4},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok || !errObj.Exit {
+			t.Errorf("expected exit got %T=(%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Code != tt.expected {
+			t.Errorf("wrong exit code. expected=%d, got=%d", tt.expected, errObj.Code)
+		}
+	}
+}
+
 func TestFunction(t *testing.T) {
 	input := "fn(x) { x + 2; };"
 	evaluated := testEval(input)
@@ -293,6 +367,241 @@ func TestFunctionCall(t *testing.T) {
 	}
 }
 
+func TestParameterKinds(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"let f = fn(x, y = 10) { x + y }; f(1)", 11},
+		{"let f = fn(x, y = 10) { x + y }; f(1, 2)", 3},
+		{"let f = fn(x, y = x * 2) { x + y }; f(3)", 9},
+		{"let f = fn(x = 1, y) { x + y }; f(y: 2)", 3},
+		{"let f = fn([a, b] = [1, 2]) { a + b }; f()", 3},
+		{"let f = fn(first, ...rest) { len(rest) }; f(1)", 0},
+		{"let f = fn(first, ...rest) { rest[1] }; f(1, 2, 3)", 3},
+		{"let f = fn(x, y = 1, ...rest) { x + y + len(rest) }; f(10, 20, 30, 40)", 32},
+		{"let f = fn(x, y) { x - y }; f(y: 1, x: 5)", 4},
+		{"let f = fn(x, y = 2, z = 3) { x * 100 + y * 10 + z }; f(1, z: 5)", 125},
+		{"let f = fn(x, y) { x }; f(1, 2, 3)", nil},
+		{"let f = fn(x, y) { x }; f(1, z: 3)", nil},
+		{"let f = fn(x, y) { x }; f(1, x: 3)", nil},
+		{"let f = fn(x, y, z = 1) { x }; f(1)", nil},
+		{"let f = fn(...rest) { rest }; f(rest: [1])", nil},
+		{"len(x: [1])", nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testInteger(t, evaluated, int64(expected))
+		default:
+			if _, ok := evaluated.(*object.Error); !ok {
+				t.Errorf("expected an error for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+			}
+		}
+	}
+}
+
+func TestPipeExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let double = fn(x) { x * 2 }; 5 |> double()", 10},
+		{"let sub = fn(a, b) { a - b }; 10 |> sub(3)", 7},
+		{"let sub = fn(a, b) { a - b }; 10 |> sub(3) |> sub(2)", 5},
+		{"let sub = fn(a, b = 1) { a - b }; (2 + 3 |> sub(b: 2)) * 2", 6},
+		{"[1, 2, 3] |> push(4) |> len()", 4},
+	}
+
+	for _, tt := range tests {
+		testInteger(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestMethodCall(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{`let p = {"name": "Al"}; p.name`, "Al"},
+		{`if {"a": 1}.b == null { 1 } else { 0 }`, 1},
+		{`let p = {"n": 2, "twice": fn(self) { self.n * 2 }}; p.twice()`, 4},
+		{`let p = {"n": 2, "add": fn(self, x, y = 0) { self.n + x + y }}; p.add(3, y: 4)`, 9},
+		{`"abc".len()`, 3},
+		{"[1, 2].push(3).len()", 3},
+		{"[1, 2, 3].tail().head()", 2},
+		{`{"a": 1}.string()`, "{a:1}"},
+		{`let p = {"len": fn(self) { 10 }}; p.len()`, 10},
+		{"1.len()", nil},
+		{`"abc".size()`, nil},
+		{`{"a": 1}.a()`, nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testInteger(t, evaluated, int64(expected))
+		case string:
+			testString(t, evaluated, expected)
+		default:
+			if _, ok := evaluated.(*object.Error); !ok {
+				t.Errorf("expected an error for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+			}
+		}
+	}
+}
+
+func TestStructs(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{`struct Person { name, age }; string(Person("Alice", 25))`, `Person{name: "Alice", age: 25}`},
+		{"struct Person { name, age }; Person(age: 25, name: 1).age", 25},
+		{`struct P { x, f }; P(2, fn(self, y) { self.x * y }).f(3)`, 6},
+		{`struct P { x }; type(P(1))`, "P"},
+		{`struct P { x }; P([]).type()`, "P"},
+		{`type("a")`, "STRING"},
+		{"struct P { x }; type(P)", "STRUCT_TYPE"},
+		{"struct P { x }; string(P)", "struct P { x }"},
+		{`struct P { x, y }; if P(1, "a") == P(1, "a") { 1 } else { 0 }`, 1},
+		{"struct P { x, y }; if P(1, 2) != P(1, 3) { 1 } else { 0 }", 1},
+		{"struct P { x }; struct Q { x }; if P(1) == Q(1) { 1 } else { 0 }", 0},
+		{`struct P { xs, h }; if P([1, [2]], {"a": [3]}) == P([1, [2]], {"a": [3]}) { 1 } else { 0 }`, 1},
+		{`struct P { xs, h }; if P([1, [2]], {"a": 3}) == P([1, [3]], {"a": 3}) { 1 } else { 0 }`, 0},
+		{`struct P { h }; if P({"a": 1, "b": null}) == P({"b": null, "a": 1}) { 1 } else { 0 }`, 1},
+		{`struct P { h }; if P({"a": 1}) != P({"a": 1, "b": 2}) { 1 } else { 0 }`, 1},
+		{"struct P { x }; if P(P([1])) == P(P([1])) { 1 } else { 0 }", 1},
+		{"struct P { x }; if P([1]) == P(1) { 1 } else { 0 }", 0},
+		{"let f = fn(x) { struct P { x }; P(x) }; f(3).x", 3},
+		{"let f = fn() { struct P { x } }; type(f())", "STRUCT_TYPE"},
+		{"struct P { x, y }; P(1)", nil},
+		{"struct P { x }; P(1, 2)", nil},
+		{"struct P { x }; P(y: 1)", nil},
+		{"struct P { x }; P(1).y", nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testInteger(t, evaluated, int64(expected))
+		case string:
+			testString(t, evaluated, expected)
+		default:
+			if _, ok := evaluated.(*object.Error); !ok {
+				t.Errorf("expected an error for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+			}
+		}
+	}
+}
+
+func TestLoops(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"let i = 0; while i < 5 { let i = i + 1 }; i", 5},
+		{"let i = 0; string(while i < 3 { let i = i + 1; i * 10 })", "[10, 20, 30]"},
+		{"let i = 0; string(while true { let i = i + 1; if i % 2 == 0 { continue }; if i > 5 { break }; i })", "[1, 3, 5]"},
+		{"string(for x in [1, 2, 3] { x * x })", "[1, 4, 9]"},
+		{`string(for k in {"b": 1, "a": 2} { k })`, "[b, a]"},
+		{`string(for c in "héy" { c + c })`, "[hh, éé, yy]"},
+		{"string(range(3)) + string(range(2, 4)) + string(range(3, 1))", "[0, 1, 2][2, 3][]"},
+		{"string(for [a, b] in [[1, 2], [3, 4]] { a + b })", "[3, 7]"},
+		{"string(for x in range(10) { if x > 2 { break } else { x } })", "[0, 1, 2]"},
+		{"string(for x in [1, 2, 3] { let y = if x == 2 { continue } else { x }; y })", "[1, 3]"},
+		{"string(for x in [1, 2, 3] { [if x == 2 { break } else { x }] })", "[[1]]"},
+		{"string(for x in [1, 2, 3] { [x, if x == 2 { continue } else { x }] })", "[[1, 1], [3, 3]]"},
+		{"string(for x in [1, 2, 3] { 10 + if x == 2 { break } else { x } })", "[11]"},
+		{"string(for x in [1, 2, 3] { string(if x == 2 { continue } else { x }) })", "[1, 3]"},
+		{"for x in [1, 2] { let last = x }; last", 2},
+		{"let f = fn(xs) { for x in xs { if x > 1 { return x } }; 0 }; f([1, 5, 7])", 5},
+		{"let f = fn() { for x in [1, 2] { fn() { x }() } }; string(f())", "[1, 2]"},
+		{"let total = 0; for n in range(100000) { let total = total + n }; total", 4999950000},
+		{"string(for x in [] { x })", "[]"},
+		{"for x in 5 { x }", nil},
+		{"for [a] in [1] { a }", nil},
+		{"range(1, true)", nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testInteger(t, evaluated, int64(expected))
+		case string:
+			testString(t, evaluated, expected)
+		default:
+			if _, ok := evaluated.(*object.Error); !ok {
+				t.Errorf("expected an error for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+			}
+		}
+	}
+}
+
+// Only the interpreter has eval, so the code isn't an input the transpilers run
+func TestEvalParseErrors(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{`eval("break")`, "Error at line 1. break outside of a loop"},
+		{`for x in [1] { eval("continue") }`, "Error at line 1. continue outside of a loop"},
+		{`eval("let")`, "Error at line 0, col 0. expected next token to be IDENT, got EOF instead"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.code)
+		err, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("expected error %q for %q, got %T (%+v)", tt.expected, tt.code, evaluated, evaluated)
+			continue
+		}
+		if err.Message != tt.expected {
+			t.Errorf("wrong error message for %q. expected=%q, got=%q", tt.code, tt.expected, err.Message)
+		}
+	}
+}
+
+func TestCompiledFunctionCall(t *testing.T) {
+	env := object.NewEnvironment()
+	env.Set("double", &object.CompiledFunction{
+		Parameters: []object.Parameter{{Source: "x", Name: "x"}},
+		Body:       "(x * 2)",
+		Fn: func(args ...object.Object) object.Object {
+			return Infix("*", args[0], &object.Integer{Value: 2})
+		},
+	})
+
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"double(5)", 10},
+		{"let twice = fn(f, x) { f(f(x)) }; twice(double, 3)", 12},
+		{"double()", "function fn(x) {\n(x * 2)\n} is missing 1 parameters"},
+		{"double(x: 4)", 8},
+		{"double(1, 2)", "function fn(x) {\n(x * 2)\n} takes at most 1 arguments, got 2"},
+	}
+
+	for _, tt := range tests {
+		evaluated := Eval(parser.New(lexer.New(tt.input)).ParseProgram(), env)
+		switch expected := tt.expected.(type) {
+		case int:
+			testInteger(t, evaluated, int64(expected))
+		case string:
+			err, ok := evaluated.(*object.Error)
+			if !ok || err.Message != expected {
+				t.Errorf("expected error %q, got %+v", expected, evaluated)
+			}
+		}
+	}
+}
+
 func TestErrorHandling(t *testing.T) {
 	tests := []struct {
 		input           string
@@ -337,6 +646,66 @@ func TestErrorHandling(t *testing.T) {
 			`,
 			"Operation + between BOOLEAN and BOOLEAN not implemented!",
 		},
+		{
+			"match 5 { 1 => 2, [a] => a }",
+			"no pattern matches 5",
+		},
+		{
+			"let f = fn(x) { match x { n if n > 0 => n } }; f(1) + f(-1)",
+			"no pattern matches -1",
+		},
+		{
+			`let {"a": a} = {"b": 1}`,
+			"{b:1} does not match {a:a}",
+		},
+		{
+			"let f = fn(x, [y]) { y }; f(1, [2, 3])",
+			"[2, 3] does not match [y]",
+		},
+		{
+			"let f = fn(x) { x }; f(1, 2)",
+			"function fn(x) {\nx\n} takes at most 1 arguments, got 2",
+		},
+		{
+			"let f = fn(x) { x }; f(y: 2)",
+			"function fn(x) {\nx\n} has no parameter y",
+		},
+		{
+			"let f = fn(x) { x }; f(1, x: 2)",
+			"function fn(x) {\nx\n} got x more than once",
+		},
+		{
+			"len(x: [1])",
+			"builtin function doesn't take named arguments",
+		},
+		{
+			"struct P { x, y }; P(1)",
+			"struct P is missing 1 parameters",
+		},
+		{
+			"struct P { x }; P(1).y",
+			"P has no field y",
+		},
+		{
+			"5.len()",
+			"INTEGER has no method len",
+		},
+		{
+			`"abc".size()`,
+			"STRING has no method size",
+		},
+		{
+			"[1].name",
+			"ARRAY has no property name",
+		},
+		{
+			"for x in 5 { x }",
+			"INTEGER is not iterable",
+		},
+		{
+			"range(1, true)",
+			"argument to `range` not supported, got BOOLEAN",
+		},
 		// {
 		// 	"foobar",
 		// 	"identifier not found: foobar",
@@ -412,6 +781,21 @@ func TestEvalIfExpression(t *testing.T) {
 		{"if (null) { 3 }", nil},
 		{"if (0) { 2 } else { 5 }", 5},
 		{"if (0 + 1) { 3 } else { 5 }", 3},
+		{"if -1 { 3 } else { 5 }", 3},
+		{"if 0 { 3 }", nil},
+		{`if "" { 3 } else { 5 }`, 5},
+		{`if "a" { 3 } else { 5 }`, 3},
+		{"if [] { 3 } else { 5 }", 5},
+		{"if {} { 3 } else { 5 }", 5},
+		{"if fn() {} { 3 } else { 5 }", 3},
+		{"if 1 > 2 { 1 } else if 2 > 2 { 2 } else if 3 > 2 { 3 } else { 4 }", 3},
+		{"if 1 > 2 { 1 } else if 2 > 2 { 2 } else { 4 }", 4},
+		{"if 1 > 2 { 1 } else if 2 > 2 { 2 }", nil},
+		{"if (0) { 1 } else if (1) { 2 }", 2},
+		{"if true {}", nil},
+		{"if false { 1 } else {}", nil},
+		{"let f = fn(x) { if x == 1 { return 10 } else if x == 2 { return 20 }; 30 }; f(2)", 20},
+		{"let f = fn(x) { if x == 1 { return 10 } else if x == 2 { return 20 }; 30 }; f(3)", 30},
 	}
 
 	for _, tt := range tests {
@@ -425,6 +809,87 @@ func TestEvalIfExpression(t *testing.T) {
 	}
 }
 
+func TestMatchExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{`match 1 { 1 => "one", _ => "other" }`, "one"},
+		{`match 2 { 1 => "one", _ => "other" }`, "other"},
+		{`match -1 { 1 => "one", -1 => "minus one" }`, "minus one"},
+		{`match "hi" { 1 => "one", "hi" => "greeting" }`, "greeting"},
+		{`match true { 1 => "one", true => "yes" }`, "yes"},
+		{`match null { 0 => "zero", false => "false", null => "null" }`, "null"},
+		{`match 1 { "1" => "string", true => "boolean", 1 => "integer" }`, "integer"},
+		{"match 5 { n => n * 2 }", 10},
+		{"match [] { [] => 0, [a] => a }", 0},
+		{"match [7] { [] => 0, [a] => a }", 7},
+		{"match [1, 2] { [a] => a, [a, b] => a + b }", 3},
+		{"match [1, 2, 3] { [a, b] => 0, [h, ...t] => h + len(t) }", 3},
+		{"match [1] { [h, ...t] => len(t) }", 0},
+		{"match [] { [h, ...t] => 1, _ => 2 }", 2},
+		{"match [[1, 2], 3] { [[a, _], b] => a + b }", 4},
+		{`match {"name": "Al", "age": 20} { {"name": n} => len(n) }`, 2},
+		{`match {"age": 20} { {"name": n} => 1, {"age": a} => a }`, 20},
+		{`match {1: [5, 6]} { {1: [x, ..._]} => x }`, 5},
+		{`match [1, 2] { {"a": a} => a, _ => 9 }`, 9},
+		{"match 5 { n if n > 10 => 1, n if n > 3 => 2, _ => 3 }", 2},
+		{"match 5 { n if n > 10 => n }; 1", nil},
+		{"let n = 1; match 2 { n => n }; n", 1},
+		{"let f = fn(x) { match x { 0 => if true { return 10 }, _ => 20 }; 30 }; f(0)", 10},
+		{"let f = fn(x) { match x { [a, ...rest] => a + f(rest), [] => 0 } }; f([1, 2, 3])", 6},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testInteger(t, evaluated, int64(expected))
+		case string:
+			testString(t, evaluated, expected)
+		default:
+			if _, ok := evaluated.(*object.Error); !ok {
+				t.Errorf("expected an error for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+			}
+		}
+	}
+}
+
+func TestDestructuring(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"let [a, b] = [1, 2]; a * 10 + b", 12},
+		{"let [a, ...rest] = [1, 2, 3]; len(rest)", 2},
+		{"let [_, [x, _]] = [0, [5, 6]]; x", 5},
+		{`let {"name": n, "age": a} = {"age": 20, "name": "Al", "x": 1}; n`, "Al"},
+		{`let {"age": a} = {"age": 20}; a`, 20},
+		{"let f = fn() { let [a] = [7] }; f()[0]", 7},
+		{"let [a, b] = [1]; a", nil},
+		{`let {"a": a} = [1]; a`, nil},
+		{"let swap = fn([a, b]) { [b, a] }; swap([1, 2])[0]", 2},
+		{`let name = fn({"name": n}, suffix) { n + suffix }; name({"name": "Al"}, "!")`, "Al!"},
+		{"let f = fn(x) { let [h, ...t] = x; h + len(t) }; f([10, 20, 30])", 12},
+		{"let f = fn([a, b]) { a }; f([1])", nil},
+		{"let f = fn([a], [b]) { a + b }; f([1], [2])", 3},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testInteger(t, evaluated, int64(expected))
+		case string:
+			testString(t, evaluated, expected)
+		default:
+			if _, ok := evaluated.(*object.Error); !ok {
+				t.Errorf("expected an error for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+			}
+		}
+	}
+}
+
 func TestLetStatements(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -440,6 +905,20 @@ func TestLetStatements(t *testing.T) {
 	}
 }
 
+func TestUnboundIdentifiers(t *testing.T) {
+	tests := []struct {
+		input string
+	}{
+		{"missing"},
+		{"let f = fn() { missing }; f()"},
+		{"let f = fn(x) { x }; f(missing)"},
+		{"match 1 { a => a }; a"},
+	}
+	for _, tt := range tests {
+		testNull(t, testEval(tt.input))
+	}
+}
+
 func testNull(t *testing.T, evaluated object.Object) bool {
 	if evaluated != NULL {
 		t.Errorf("expected Null got %T=(%v)", evaluated, evaluated)
@@ -486,6 +965,8 @@ func TestReturnStatements(t *testing.T) {
 			f(10);`,
 			20,
 		},
+		{"let f = fn() { let a = [if true { return 5 }]; 1 }; f()", 5},
+		{"let f = fn(x) { 10 * match x { 1 => if true { return 2 }, _ => 3 } }; f(1) + f(2)", 32},
 	}
 
 	for _, tt := range tests {
@@ -524,6 +1005,8 @@ func TestEvalInteger(t *testing.T) {
 		{"5 % 10", 5},
 		{"10 % 5", 0},
 		{"10 % 10", 0},
+		{"6 & 3", 2},
+		{"6 | 3", 7},
 	}
 
 	for _, tt := range tests {
@@ -573,6 +1056,15 @@ func TestEvalBoolean(t *testing.T) {
 		{"!false", true},
 		{"!0", true},
 		{"!5", false},
+		{"!-1", false},
+		{"!null", true},
+		{`!""`, true},
+		{`!"a"`, false},
+		{"![]", true},
+		{"![0]", false},
+		{"!{}", true},
+		{"!!{1: 2}", true},
+		{"!len", false},
 		{"5 > 5", false},
 		{"5 == 5", true},
 		{"5 < 5", false},
@@ -606,6 +1098,86 @@ func TestEvalBoolean(t *testing.T) {
 	}
 }
 
+func TestIsTruthy(t *testing.T) {
+	tests := []struct {
+		obj      object.Object
+		expected bool
+	}{
+		{nil, false},
+		{NULL, false},
+		{TRUE, true},
+		{FALSE, false},
+		{&object.Integer{Value: 0}, false},
+		{&object.Integer{Value: -1}, true},
+		{&object.String{Value: ""}, false},
+		{&object.String{Value: " "}, true},
+		{&object.Array{}, false},
+		{&object.Array{Elements: []object.Object{NULL}}, true},
+		{object.NewHash(), false},
+		{testEval(`{"a": 1}`), true},
+		{testEval("fn() {}"), true},
+		{testEval("len"), true},
+		{testEval("macro(x) { `$x` }"), true},
+	}
+
+	for _, tt := range tests {
+		if got := IsTruthy(tt.obj); got != tt.expected {
+			t.Errorf("wrong truthiness for %T=(%v). expected=%t, got=%t", tt.obj, tt.obj, tt.expected, got)
+		}
+	}
+}
+
+func TestUnknownPrefixOperator(t *testing.T) {
+	evaluated := Prefix("+", &object.Integer{Value: 1})
+
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+	if errObj.Message != "unknown operator: +" {
+		t.Errorf("wrong error message. got=%q", errObj.Message)
+	}
+}
+
+func TestLogicalOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"true && false", false},
+		{"true || false", true},
+		{"false || false", false},
+		{"1 && 2", 2},
+		{"0 && 2", 0},
+		{"0 || 3", 3},
+		{"null || 3", 3},
+		{"null && 3", nil},
+		{"false && 1 + true", false},
+		{"true || 1 + true", true},
+		{"1 < 2 && 3 > 4 || 5", 5},
+		{`"" || -1`, -1},
+		{"[1] && {} || 2", 2},
+		{"let f = fn(n) { n > 1 && f(n - 1) || n }; f(3)", 1},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testInteger(t, evaluated, int64(expected))
+		case bool:
+			testBoolean(t, evaluated, expected)
+		default:
+			testNull(t, evaluated)
+		}
+	}
+
+	evaluated := testEval("true && 1 + true")
+	if err, ok := evaluated.(*object.Error); !ok || err.Message != "Operation + between INTEGER and BOOLEAN not implemented!" {
+		t.Errorf("expected the right side to run. got=%T (%+v)", evaluated, evaluated)
+	}
+}
+
 func testBoolean(t *testing.T, evaluated object.Object, expected bool) bool {
 	obj, ok := evaluated.(*object.Boolean)
 	if !ok {
@@ -634,6 +1206,13 @@ func testInteger(t *testing.T, evaluated object.Object, expected int64) bool {
 	return true
 }
 
+func TestEvalWithComments(t *testing.T) {
+	input := "// double it\nlet f = fn(x) { // start\n\tx * 2 // result\n\t// end\n}\nf(21) // call"
+
+	program := parser.New(lexer.NewWithComments(input)).ParseProgram()
+	testInteger(t, Eval(program, object.NewEnvironment()), 42)
+}
+
 func testEval(input string) object.Object {
 	l := lexer.New(input)
 	p := parser.New(l)
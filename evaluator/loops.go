@@ -0,0 +1,94 @@
+package evaluator
+
+import (
+	"monkey/ast"
+	"monkey/object"
+)
+
+// Loops run their body in the environment they are in and result in the array of the values
+// of the iterations that didn't continue or break
+func evalWhile(node *ast.WhileExpression, env *object.Environment) object.Object {
+	values := []object.Object{}
+	for {
+		condition := Eval(node.Condition, env)
+		if isAbrupt(condition) {
+			return condition
+		}
+		if !IsTruthy(condition) {
+			break
+		}
+
+		if result, stop := Iteration(Eval(node.Body, env), &values); stop {
+			if result != nil {
+				return result
+			}
+			break
+		}
+	}
+	return &object.Array{Elements: values}
+}
+
+func evalFor(node *ast.ForExpression, env *object.Environment) object.Object {
+	iterable := Eval(node.Iterable, env)
+	if isAbrupt(iterable) {
+		return iterable
+	}
+
+	elements, err := Elements(iterable)
+	if err != nil {
+		return err
+	}
+
+	values := []object.Object{}
+	for _, element := range elements {
+		if bound := destructure(node.Name, element, env); isError(bound) {
+			return bound
+		}
+
+		if result, stop := Iteration(Eval(node.Body, env), &values); stop {
+			if result != nil {
+				return result
+			}
+			break
+		}
+	}
+	return &object.Array{Elements: values}
+}
+
+// Adds the value of an iteration to the values of its loop unless it continued. The loop stops
+// on a break, and on returns and errors, which are then its result
+func Iteration(value object.Object, values *[]object.Object) (object.Object, bool) {
+	switch value.(type) {
+	case *object.Break:
+		return nil, true
+	case *object.Continue:
+		return nil, false
+	case *object.Return, *object.Error:
+		return value, true
+	case nil:
+		value = NULL
+	}
+	*values = append(*values, value)
+	return nil, false
+}
+
+// What a for loop goes through, the elements of an array, the keys of a hash or the characters of a string
+func Elements(iterable object.Object) ([]object.Object, *object.Error) {
+	switch iterable := iterable.(type) {
+	case *object.Array:
+		return iterable.Elements, nil
+	case *object.Hash:
+		keys := []object.Object{}
+		for _, pair := range iterable.Ordered() {
+			keys = append(keys, pair.Key)
+		}
+		return keys, nil
+	case *object.String:
+		chars := []object.Object{}
+		for _, c := range iterable.Value {
+			chars = append(chars, &object.String{Value: string(c)})
+		}
+		return chars, nil
+	}
+	return nil, newError("%s is not iterable", iterable.Type())
+}
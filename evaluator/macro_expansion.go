@@ -0,0 +1,91 @@
+package evaluator
+
+import (
+	"monkey/ast"
+	"monkey/object"
+)
+
+// Moves the top level `let name = macro(params) { ... }` definitions into env
+func DefineMacros(program *ast.Program, env *object.Environment) {
+	statements := []ast.Statement{}
+
+	for _, stmt := range program.Statements {
+		let, ok := stmt.(*ast.LetStatement)
+		if !ok {
+			statements = append(statements, stmt)
+			continue
+		}
+
+		name, isName := let.Name.(*ast.Identifier)
+		macro, ok := let.Value.(*ast.AstMacroLiteral)
+		if !ok || !isName {
+			statements = append(statements, stmt)
+			continue
+		}
+
+		env.Set(name.Value, &object.AstMacro{Parameters: macro.Parameters, Body: macro.Body, Env: env})
+	}
+
+	program.Statements = statements
+}
+
+// Replaces the calls to the macros in env with the AST they return
+func ExpandMacros(program ast.Node, env *object.Environment) (ast.Node, *object.Error) {
+	var err *object.Error
+
+	expanded := ast.Modify(program, func(node ast.Node) ast.Node {
+		call, ok := node.(*ast.CallExpression)
+		if !ok || err != nil {
+			return node
+		}
+
+		ident, ok := call.Function.(*ast.Identifier)
+		if !ok {
+			return node
+		}
+
+		obj, ok := env.Get(ident.Value)
+		if !ok {
+			return node
+		}
+
+		macro, ok := obj.(*object.AstMacro)
+		if !ok {
+			return node
+		}
+
+		if len(call.Arguments) != len(macro.Parameters) {
+			err = newError("wrong number of arguments for macro %s. got=%d, want=%d", ident.Value, len(call.Arguments), len(macro.Parameters))
+			return node
+		}
+
+		mEnv := macro.Env.SmartCopy()
+		for i, p := range macro.Parameters {
+			mEnv.Set(p.Value, &object.Quote{Node: call.Arguments[i]})
+		}
+
+		ret := Eval(macro.Body, mEnv)
+		if r, ok := ret.(*object.Return); ok {
+			ret = r.Value
+		}
+
+		switch ret := ret.(type) {
+		case *object.Error:
+			err = ret
+		case *object.Quote:
+			return ret.Node
+		default:
+			err = newError("macro %s must return a quote, got %s", ident.Value, typeOf(ret))
+		}
+		return node
+	})
+
+	return expanded, err
+}
+
+func typeOf(obj object.Object) object.ObjectType {
+	if obj == nil {
+		return object.NULL
+	}
+	return obj.Type()
+}
@@ -0,0 +1,75 @@
+package evaluator
+
+import (
+	"monkey/ast"
+	"monkey/object"
+)
+
+func buildMember(node *ast.MemberExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isAbrupt(left) {
+		return left
+	}
+	return Member(left, node.Name.Value)
+}
+
+// The value of hash.name, null when the hash has no such key, or of the field name of a struct
+func Member(receiver object.Object, name string) object.Object {
+	switch receiver := receiver.(type) {
+	case *object.Hash:
+		if pair, ok := receiver.Pairs[(&object.String{Value: name}).HashKey()]; ok {
+			return pair.Value
+		}
+		return NULL
+	case *object.Struct:
+		if value, ok := receiver.Get(name); ok {
+			return value
+		}
+		return newError("%s has no field %s", receiver.Of.Name, name)
+	}
+	return newError("%s has no property %s", receiver.Type(), name)
+}
+
+func buildMethodCall(node *ast.CallExpression, member *ast.MemberExpression, env *object.Environment) object.Object {
+	receiver := Eval(member.Left, env)
+	if isAbrupt(receiver) {
+		return receiver
+	}
+
+	args, names, err := buildArguments(node, env)
+	if err != nil {
+		return err
+	}
+	return CallMethod(receiver, member.Name.Value, args, names, env)
+}
+
+// Calls receiver.name(args), which is the function of a hash under the key name or in the
+// field name of a struct, or else the builtin called name, passing the receiver as the first argument
+func CallMethod(receiver object.Object, name string, args []object.Object, names []string, env *object.Environment) object.Object {
+	method := lookupMethod(receiver, name, env)
+	if isError(method) {
+		return method
+	}
+	return CallNamed(method, append([]object.Object{receiver}, args...), names)
+}
+
+func lookupMethod(receiver object.Object, name string, env *object.Environment) object.Object {
+	switch receiver := receiver.(type) {
+	case *object.Hash:
+		if pair, ok := receiver.Pairs[(&object.String{Value: name}).HashKey()]; ok {
+			return pair.Value
+		}
+	case *object.Struct:
+		if value, ok := receiver.Get(name); ok {
+			return value
+		}
+	case *object.String, *object.Array:
+	default:
+		return newError("%s has no method %s", receiver.Type(), name)
+	}
+
+	if builtin, ok := Builtin(name, env).(*object.Builtin); ok {
+		return builtin
+	}
+	return newError("%s has no method %s", TypeName(receiver), name)
+}
@@ -0,0 +1,120 @@
+package evaluator
+
+import (
+	"monkey/ast"
+	"monkey/object"
+)
+
+func buildMatch(node *ast.MatchExpression, env *object.Environment) object.Object {
+	value := Eval(node.Value, env)
+	if isAbrupt(value) {
+		return value
+	}
+
+	for _, arm := range node.Arms {
+		armEnv := env.SmartCopy()
+		if !bindPattern(arm.Pattern, value, armEnv) {
+			continue
+		}
+
+		if arm.Guard != nil {
+			guard := Eval(arm.Guard, armEnv)
+			if isAbrupt(guard) {
+				return guard
+			}
+			if !IsTruthy(guard) {
+				continue
+			}
+		}
+
+		return Eval(arm.Value, armEnv)
+	}
+
+	return newError("no pattern matches %s", value.Inspect())
+}
+
+// Whether the value has the shape of the pattern, setting the names it binds in env
+func bindPattern(pattern ast.Expression, value object.Object, env *object.Environment) bool {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		switch pattern.Value {
+		case "_":
+			return true
+		case "null":
+			return value == NULL
+		}
+		env.Set(pattern.Value, value)
+		return true
+	case *ast.ArrayLiteral:
+		arr, ok := value.(*object.Array)
+		if !ok {
+			return false
+		}
+
+		elements := pattern.Elements
+		var rest *ast.RestElement
+		if n := len(elements); n > 0 {
+			if r, ok := elements[n-1].(*ast.RestElement); ok {
+				rest, elements = r, elements[:n-1]
+			}
+		}
+
+		if len(arr.Elements) < len(elements) || rest == nil && len(arr.Elements) != len(elements) {
+			return false
+		}
+		for i, el := range elements {
+			if !bindPattern(el, arr.Elements[i], env) {
+				return false
+			}
+		}
+
+		if rest != nil {
+			remaining := append([]object.Object{}, arr.Elements[len(elements):]...)
+			return bindPattern(rest.Name, &object.Array{Elements: remaining}, env)
+		}
+		return true
+	case *ast.HashLiteral:
+		hash, ok := value.(*object.Hash)
+		if !ok {
+			return false
+		}
+
+		for _, pair := range pattern.Pairs {
+			key, ok := Eval(pair.Key, env).(object.Hashable)
+			if !ok {
+				return false
+			}
+			found, ok := hash.Pairs[key.HashKey()]
+			if !ok || !bindPattern(pair.Value, found.Value, env) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return MatchesLiteral(Eval(pattern, env), value)
+}
+
+// Binds the name of a let or parameter, or the names of its pattern, erroring when the value doesn't match
+func destructure(target ast.Expression, value object.Object, env *object.Environment) object.Object {
+	if ident, ok := target.(*ast.Identifier); ok {
+		return env.Set(ident.Value, value)
+	}
+	if !bindPattern(target, value, env) {
+		return newError("%s does not match %s", value.Inspect(), target.String())
+	}
+	return value
+}
+
+// Literal patterns match values of the same type and value
+func MatchesLiteral(literal object.Object, value object.Object) bool {
+	if literal == NULL {
+		return value == NULL
+	}
+	l, ok := literal.(object.Hashable)
+	if !ok {
+		return false
+	}
+	v, ok := value.(object.Hashable)
+	return ok && l.HashKey() == v.HashKey()
+}
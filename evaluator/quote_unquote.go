@@ -0,0 +1,88 @@
+package evaluator
+
+import (
+	"fmt"
+	"monkey/ast"
+	"monkey/object"
+	"monkey/token"
+)
+
+func isCallTo(node ast.Node, name string) bool {
+	call, ok := node.(*ast.CallExpression)
+	if !ok {
+		return false
+	}
+	ident, ok := call.Function.(*ast.Identifier)
+	return ok && ident.Value == name
+}
+
+func buildQuote(node *ast.CallExpression, env *object.Environment) object.Object {
+	if len(node.Arguments) != 1 {
+		return newError("wrong number of arguments for quote. got=%d, want=1", len(node.Arguments))
+	}
+
+	// The first unquote that fails is the error of the quote
+	var err *object.Error
+	quoted := ast.Modify(node.Arguments[0], func(node ast.Node) ast.Node {
+		if err != nil || !isCallTo(node, "unquote") {
+			return node
+		}
+
+		call := node.(*ast.CallExpression)
+		if len(call.Arguments) != 1 {
+			err = newError("wrong number of arguments for unquote. got=%d, want=1", len(call.Arguments))
+			return node
+		}
+
+		value := Eval(call.Arguments[0], env)
+		if e, ok := value.(*object.Error); ok {
+			err = e
+			return node
+		}
+
+		converted, e := buildNode(value)
+		if e != nil {
+			err = e
+			return node
+		}
+		return converted
+	})
+	if err != nil {
+		return err
+	}
+
+	return &object.Quote{Node: quoted}
+}
+
+// Turns an evaluated object back into the AST node that produces it, objects without one are an error
+func buildNode(obj object.Object) (ast.Expression, *object.Error) {
+	switch obj := obj.(type) {
+	case *object.Quote:
+		if exp, ok := obj.Node.(ast.Expression); ok {
+			return exp, nil
+		}
+	case *object.Integer:
+		literal := fmt.Sprintf("%d", obj.Value)
+		return &ast.IntegerLiteral{Token: token.Token{Type: token.INT, Literal: literal}, Value: obj.Value}, nil
+	case *object.Boolean:
+		if obj.Value {
+			return &ast.BooleanLiteral{Token: token.Token{Type: token.TRUE, Literal: "true"}, Value: true}, nil
+		}
+		return &ast.BooleanLiteral{Token: token.Token{Type: token.FALSE, Literal: "false"}, Value: false}, nil
+	case *object.String:
+		return &ast.StringLiteral{Token: token.Token{Type: token.STRING, Literal: obj.Value}, Value: obj.Value}, nil
+	case *object.Null:
+		return &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: "null"}, Value: "null"}, nil
+	case *object.Array:
+		arr := &ast.ArrayLiteral{ExpressionsContainer: ast.ExpressionsContainer{Token: token.Token{Type: token.LBRACKET, Literal: "["}}}
+		for _, e := range obj.Elements {
+			el, err := buildNode(e)
+			if err != nil {
+				return nil, err
+			}
+			arr.Elements = append(arr.Elements, el)
+		}
+		return arr, nil
+	}
+	return nil, newError("cannot unquote %s", typeOf(obj))
+}
@@ -0,0 +1,98 @@
+package evaluator
+
+import (
+	"monkey/object"
+	"testing"
+)
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`quote(5)`, `5`},
+		{`quote(5 + 8)`, `(5 + 8)`},
+		{`quote(foobar)`, `foobar`},
+		{`quote(foobar + barfoo)`, `(foobar + barfoo)`},
+	}
+
+	for _, tt := range tests {
+		testQuote(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestQuoteUnquote(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`quote(unquote(4))`, `4`},
+		{`quote(unquote(4 + 4))`, `8`},
+		{`quote(8 + unquote(4 + 4))`, `(8 + 8)`},
+		{`quote(unquote(4 + 4) + 8)`, `(8 + 8)`},
+		{`let foobar = 8; quote(foobar)`, `foobar`},
+		{`let foobar = 8; quote(unquote(foobar))`, `8`},
+		{`quote(unquote(true))`, `true`},
+		{`quote(unquote(true == false))`, `false`},
+		{`quote(unquote(quote(4 + 4)))`, `(4 + 4)`},
+		{`quote(unquote("hi"))`, `hi`},
+		{`quote(unquote([1, 2 * 2]))`, `[1, 4]`},
+		{`quote(unquote(null))`, `null`},
+		{
+			`let quotedInfix = quote(4 + 4); quote(unquote(4 + 4) + unquote(quotedInfix))`,
+			`(8 + (4 + 4))`,
+		},
+		{
+			`let f = fn(x) { quote(unquote(x) + 1) }; f(1); f(2)`,
+			`(2 + 1)`,
+		},
+	}
+
+	for _, tt := range tests {
+		testQuote(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestUnquoteErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`quote(unquote(fn(x) { x }))`, "cannot unquote FUNCTION"},
+		{`quote(1 + unquote({"a": 1}))`, "cannot unquote HASH"},
+		{`quote(unquote([1, len]))`, "cannot unquote BUILTIN"},
+		{`quote(unquote(true + 1))`, "Operation + between BOOLEAN and INTEGER not implemented!"},
+		{`quote(unquote(1, 2))`, "wrong number of arguments for unquote. got=2, want=1"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		err, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("expected error %q, got %T=(%v)", tt.expected, evaluated, evaluated)
+			continue
+		}
+		if err.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expected, err.Message)
+		}
+	}
+}
+
+func testQuote(t *testing.T, evaluated object.Object, expected string) bool {
+	quote, ok := evaluated.(*object.Quote)
+	if !ok {
+		t.Errorf("expected Quote got %T=(%v)", evaluated, evaluated)
+		return false
+	}
+
+	if quote.Node == nil {
+		t.Errorf("quote.Node is nil")
+		return false
+	}
+
+	if quote.Node.String() != expected {
+		t.Errorf("expected %s got %s", expected, quote.Node.String())
+		return false
+	}
+	return true
+}
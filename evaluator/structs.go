@@ -0,0 +1,58 @@
+package evaluator
+
+import "monkey/object"
+
+// Structs are equal when they are of the same struct type and their fields have equal values
+func structsEqual(left, right *object.Struct) bool {
+	if left.Of != right.Of {
+		return false
+	}
+	for i := range left.Values {
+		if !valuesEqual(left.Values[i], right.Values[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Arrays are equal element by element and hashes when they have the same keys with equal values,
+// other values when they are ==
+func valuesEqual(left, right object.Object) bool {
+	switch left := left.(type) {
+	case *object.Array:
+		right, ok := right.(*object.Array)
+		if !ok || len(left.Elements) != len(right.Elements) {
+			return false
+		}
+		for i := range left.Elements {
+			if !valuesEqual(left.Elements[i], right.Elements[i]) {
+				return false
+			}
+		}
+		return true
+	case *object.Hash:
+		right, ok := right.(*object.Hash)
+		if !ok || len(left.Pairs) != len(right.Pairs) {
+			return false
+		}
+		for key, pair := range left.Pairs {
+			other, ok := right.Pairs[key]
+			if !ok || !valuesEqual(pair.Value, other.Value) {
+				return false
+			}
+		}
+		return true
+	case *object.Struct:
+		right, ok := right.(*object.Struct)
+		return ok && structsEqual(left, right)
+	}
+	return Infix("==", left, right) == TRUE
+}
+
+// Name of the type of the value, the name of the struct for struct values
+func TypeName(value object.Object) string {
+	if s, ok := value.(*object.Struct); ok {
+		return s.Of.Name
+	}
+	return string(value.Type())
+}
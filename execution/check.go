@@ -0,0 +1,69 @@
+package execution
+
+import (
+	"fmt"
+	"io"
+	"monkey/ast"
+	"monkey/evaluator"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/token"
+	"os"
+)
+
+// Parses and expands the macros of the file without running it
+func CheckCode(file string) error {
+	program, err := parseFile(file)
+	if err != nil {
+		return err
+	}
+
+	_, err = expand(file, program)
+	return err
+}
+
+// Prints the tokens of the file, one per line
+func PrintTokens(out io.Writer, file string) bool {
+	text, err := os.ReadFile(file)
+	if err != nil {
+		return report(err)
+	}
+
+	printTokens(out, string(text))
+	return true
+}
+
+// Prints the statements of the file as the parser understood them, one per line
+func PrintAst(out io.Writer, file string) bool {
+	program, err := parseFile(file)
+	if err != nil {
+		return report(err)
+	}
+
+	printStatements(out, program)
+	return true
+}
+
+func printTokens(out io.Writer, text string) {
+	l := lexer.New(text)
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		fmt.Fprintf(out, "%-10s %q\n", tok.Type, tok.Literal)
+	}
+}
+
+func printStatements(out io.Writer, program *ast.Program) {
+	for _, stmt := range program.Statements {
+		fmt.Fprintf(out, "%T %s\n", stmt, stmt.String())
+	}
+}
+
+func expand(file string, program *ast.Program) (*ast.Program, error) {
+	env := object.NewEnvironment()
+	evaluator.DefineMacros(program, env)
+	expanded, expandErr := evaluator.ExpandMacros(program, env)
+	if expandErr != nil {
+		return nil, &ParseError{File: file, Messages: []string{expandErr.Inspect()}}
+	}
+
+	return expanded.(*ast.Program), nil
+}
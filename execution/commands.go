@@ -0,0 +1,166 @@
+package execution
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"monkey/evaluator"
+	"monkey/object"
+	"os"
+	"strings"
+	"time"
+)
+
+// State of an interactive session
+type repl struct {
+	out io.Writer
+	// Where the errors of the code and of the commands go
+	errOut io.Writer
+	opts   Options
+	env    *object.Environment
+	// The ExitError of the code that ended the session
+	exit error
+}
+
+type replCommand struct {
+	name        string
+	args        string
+	description string
+	run         func(r *repl, arg string) bool
+}
+
+var replCommands []replCommand
+
+func init() {
+	replCommands = []replCommand{
+		{"env", "", "list the names defined and their values", (*repl).listEnv},
+		{"type", "expr", "print the type of the value of the expression", (*repl).printType},
+		{"ast", "expr", "print the statements of the code as parsed", (*repl).printAst},
+		{"tokens", "expr", "print the tokens of the code", (*repl).printTokens},
+		{"load", "file", "run a file keeping what it defines", (*repl).load},
+		{"reset", "", "forget every name defined", (*repl).resetCommand},
+		{"time", "expr", "print the value of the expression and how long it took", (*repl).time},
+		{"help", "", "list the commands", (*repl).help},
+	}
+}
+
+func (r *repl) reset() {
+	r.env = object.NewEnvironment()
+	r.env.Set("echo", evaluator.Echo(r.out))
+}
+
+// Evaluates the code printing its errors, returns false when it called exit
+func (r *repl) eval(code string) (object.Object, bool) {
+	result, err := evalCode(code, "", r.env, r.opts)
+	var exit *ExitError
+	if errors.As(err, &exit) {
+		r.exit = err
+		return nil, false
+	}
+	if err != nil {
+		fmt.Fprintln(r.errOut, err)
+	}
+	return result, true
+}
+
+// Evaluates the code printing its value
+func (r *repl) print(code string) bool {
+	result, ok := r.eval(code)
+	if result != nil {
+		fmt.Fprintln(r.out, result.Inspect())
+	}
+	return ok
+}
+
+// Runs a line starting with a colon, returns false when the session ends
+func (r *repl) command(line string) bool {
+	name, arg, _ := strings.Cut(strings.TrimPrefix(line, ":"), " ")
+	arg = strings.TrimSpace(arg)
+
+	for _, c := range replCommands {
+		if c.name != name {
+			continue
+		}
+		if c.args != "" && arg == "" {
+			fmt.Fprintf(r.errOut, "usage: :%s %s\n", c.name, c.args)
+			return true
+		}
+		return c.run(r, arg)
+	}
+
+	fmt.Fprintf(r.errOut, "unknown command :%s, try :help\n", name)
+	return true
+}
+
+func (r *repl) listEnv(arg string) bool {
+	for _, name := range r.env.Names() {
+		value, _ := r.env.Get(name)
+		if _, ok := value.(*object.Builtin); ok {
+			continue
+		}
+		fmt.Fprintf(r.out, "%s = %s\n", name, value.Inspect())
+	}
+	return true
+}
+
+func (r *repl) printType(arg string) bool {
+	result, ok := r.eval(arg)
+	if result != nil {
+		fmt.Fprintln(r.out, evaluator.TypeName(result))
+	}
+	return ok
+}
+
+func (r *repl) printAst(arg string) bool {
+	program, err := parseCode(arg, "")
+	if err != nil {
+		fmt.Fprintln(r.errOut, err)
+		return true
+	}
+
+	printStatements(r.out, program)
+	return true
+}
+
+func (r *repl) printTokens(arg string) bool {
+	printTokens(r.out, arg)
+	return true
+}
+
+func (r *repl) load(arg string) bool {
+	text, err := os.ReadFile(arg)
+	if err != nil {
+		fmt.Fprintln(r.errOut, err)
+		return true
+	}
+
+	_, err = evalCode(string(text), arg, r.env, r.opts)
+	var exit *ExitError
+	if errors.As(err, &exit) {
+		r.exit = err
+		return false
+	}
+	if err != nil {
+		fmt.Fprintln(r.errOut, err)
+	}
+	return true
+}
+
+func (r *repl) resetCommand(arg string) bool {
+	r.reset()
+	return true
+}
+
+func (r *repl) time(arg string) bool {
+	start := time.Now()
+	ok := r.print(arg)
+	fmt.Fprintln(r.out, time.Since(start))
+	return ok
+}
+
+func (r *repl) help(arg string) bool {
+	for _, c := range replCommands {
+		fmt.Fprintf(r.out, "%-14s %s\n", strings.TrimSpace(":"+c.name+" "+c.args), c.description)
+	}
+	return true
+}
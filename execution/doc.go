@@ -0,0 +1,63 @@
+package execution
+
+import (
+	"fmt"
+	"io"
+	"monkey/ast"
+	"strings"
+)
+
+// Prints the bindings at the top level of the file with their doc comments
+func PrintDocs(out io.Writer, file string) bool {
+	program, err := parseFile(file)
+	if err != nil {
+		return report(err)
+	}
+
+	first := true
+	for _, stmt := range program.Statements {
+		var header, doc string
+		switch stmt := stmt.(type) {
+		case *ast.LetStatement:
+			header, doc = signature(stmt), stmt.Doc
+		case *ast.StructStatement:
+			header, doc = stmt.String(), stmt.Doc
+		default:
+			continue
+		}
+
+		if !first {
+			fmt.Fprintln(out)
+		}
+		first = false
+
+		fmt.Fprintln(out, header)
+		if doc != "" {
+			fmt.Fprintln(out, "\t"+strings.ReplaceAll(doc, "\n", "\n\t"))
+		}
+	}
+	return true
+}
+
+func signature(let *ast.LetStatement) string {
+	name := let.Name.String()
+	params := []string{}
+	switch value := let.Value.(type) {
+	case *ast.FunctionLiteral:
+		for _, p := range value.Parameters {
+			params = append(params, p.String())
+		}
+		return "fn " + name + "(" + strings.Join(params, ", ") + ")"
+	case *ast.MacroLiteral:
+		for i, p := range value.Parameters {
+			params = append(params, p.Value+": "+value.Pattern[i].String())
+		}
+		return "macro " + name + "(" + strings.Join(params, ", ") + ")"
+	case *ast.AstMacroLiteral:
+		for _, p := range value.Parameters {
+			params = append(params, p.Value)
+		}
+		return "macro " + name + "(" + strings.Join(params, ", ") + ")"
+	}
+	return "let " + name
+}
@@ -0,0 +1,37 @@
+package execution
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPrintDocs(t *testing.T) {
+	code := `/// Adds two numbers
+///
+/// Works with strings too
+let add = fn(a, b) { a + b }
+
+let pi = 3
+/// Repeats the code
+let twice = macro(code: string) { ` + "`$code; $code`" + ` }
+echo(pi)
+/// A point in the plane
+struct Point { x, y }
+`
+	file := filepath.Join(t.TempDir(), "a.mky")
+	if err := os.WriteFile(file, []byte(code), 0644); err != nil {
+		t.Fatalf("could not write %s: %s", file, err)
+	}
+
+	var out bytes.Buffer
+	if !PrintDocs(&out, file) {
+		t.Fatalf("could not print the docs")
+	}
+
+	expected := "fn add(a, b)\n\tAdds two numbers\n\t\n\tWorks with strings too\n\nlet pi\n\nmacro twice(code: string)\n\tRepeats the code\n\nstruct Point { x, y }\n\tA point in the plane\n"
+	if out.String() != expected {
+		t.Errorf("wrong docs. expected=%q, got=%q", expected, out.String())
+	}
+}
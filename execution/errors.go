@@ -0,0 +1,55 @@
+package execution
+
+import (
+	"fmt"
+	"monkey/object"
+	"strings"
+)
+
+// The program could not be parsed or its macros could not be expanded
+type ParseError struct {
+	File     string
+	Messages []string
+}
+
+func (e *ParseError) Error() string {
+	lines := []string{}
+	for _, msg := range e.Messages {
+		lines = append(lines, prefixed(e.File, msg))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// The program failed while running
+type RuntimeError struct {
+	File    string
+	Message string
+}
+
+func (e *RuntimeError) Error() string {
+	return prefixed(e.File, e.Message)
+}
+
+// The program called exit
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// Code typed in the REPL has no file
+func prefixed(file string, message string) string {
+	if file == "" {
+		return message
+	}
+	return file + ": " + message
+}
+
+func runtimeError(file string, err *object.Error) error {
+	if err.Exit {
+		return &ExitError{Code: err.Code}
+	}
+	return &RuntimeError{File: file, Message: err.Message}
+}
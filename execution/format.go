@@ -0,0 +1,37 @@
+package execution
+
+import (
+	"fmt"
+	"io"
+	"monkey/formatter"
+	"monkey/lexer"
+	"monkey/parser"
+	"os"
+)
+
+// Prints the file formatted, returns whether it succeeded. With check it only
+// prints the name of the file when its formatting differs, failing then.
+func FormatCode(out io.Writer, file string, check bool) bool {
+	text, err := os.ReadFile(file)
+	if err != nil {
+		return report(err)
+	}
+
+	p := parser.New(lexer.NewWithComments(string(text)))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return report(&ParseError{File: file, Messages: p.Errors()})
+	}
+
+	formatted := formatter.FormatSource(program, string(text))
+	if !check {
+		fmt.Fprint(out, formatted)
+		return true
+	}
+
+	if formatted != string(text) {
+		fmt.Fprintln(out, file)
+		return false
+	}
+	return true
+}
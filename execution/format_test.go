@@ -0,0 +1,38 @@
+package execution
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFormatCodeCheck(t *testing.T) {
+	tests := []struct {
+		code      string
+		formatted bool
+	}{
+		{"let x = 1 // one\nx\n", true},
+		{"let x=1;x", false},
+	}
+
+	for _, tt := range tests {
+		file := filepath.Join(t.TempDir(), "a.mky")
+		if err := os.WriteFile(file, []byte(tt.code), 0644); err != nil {
+			t.Fatalf("could not write %s: %s", file, err)
+		}
+
+		var out bytes.Buffer
+		if ok := FormatCode(&out, file, true); ok != tt.formatted {
+			t.Errorf("wrong result for %q. expected=%t, got=%t", tt.code, tt.formatted, ok)
+		}
+
+		expected := ""
+		if !tt.formatted {
+			expected = file + "\n"
+		}
+		if out.String() != expected {
+			t.Errorf("wrong output for %q. expected=%q, got=%q", tt.code, expected, out.String())
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package execution
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"monkey/evaluator"
+	"monkey/object"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Collects the files emitted by a script to write them all at once
+type Generator struct {
+	Dir    string
+	DryRun bool
+
+	files map[string]string
+}
+
+func NewGenerator(dir string, dryRun bool) *Generator {
+	return &Generator{Dir: dir, DryRun: dryRun, files: map[string]string{}}
+}
+
+// Content is normalized so the same script always generates the same bytes
+func (g *Generator) Emit(path string, content string) error {
+	clean := filepath.ToSlash(filepath.Clean(path))
+	if filepath.IsAbs(path) || clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+		return fmt.Errorf("emit path %q must be relative to the output directory", path)
+	}
+
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.TrimRight(content, "\n") + "\n"
+
+	if previous, ok := g.files[clean]; ok && previous != content {
+		return fmt.Errorf("file %q emitted twice with different content", clean)
+	}
+
+	g.files[clean] = content
+	return nil
+}
+
+func (g *Generator) Builtin() *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return &object.Error{Message: fmt.Sprintf("wrong number of arguments for emit. got=%d, want=2", len(args))}
+			}
+
+			path, ok := args[0].(*object.String)
+			if !ok {
+				return &object.Error{Message: fmt.Sprintf("path to `emit` must be STRING, got %s", args[0].Type())}
+			}
+
+			if err := g.Emit(path.Value, args[1].Inspect()); err != nil {
+				return &object.Error{Message: err.Error()}
+			}
+			return args[1]
+		},
+	}
+}
+
+func (g *Generator) Paths() []string {
+	paths := []string{}
+	for path := range g.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Writes the emitted files, on dry runs shows how they differ from the existing
+// ones instead. Returns whether any file was or would be changed.
+func (g *Generator) Flush(out io.Writer) (bool, error) {
+	changed := false
+
+	for _, path := range g.Paths() {
+		target := filepath.Join(g.Dir, filepath.FromSlash(path))
+		content := g.files[path]
+
+		existing, err := os.ReadFile(target)
+		if err != nil && !os.IsNotExist(err) {
+			return changed, err
+		}
+
+		if err == nil && bytes.Equal(existing, []byte(content)) {
+			continue
+		}
+		changed = true
+
+		if g.DryRun {
+			fmt.Fprint(out, diff(path, string(existing), content))
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return changed, err
+		}
+		if err := os.WriteFile(target, []byte(content), 0o644); err != nil {
+			return changed, err
+		}
+		fmt.Fprintf(out, "wrote %s\n", target)
+	}
+
+	return changed, nil
+}
+
+func GenerateCode(out io.Writer, file string, gen *Generator) bool {
+	env := object.NewEnvironment()
+	env.Set("emit", gen.Builtin())
+	env.Set("echo", evaluator.Echo(out))
+
+	if _, err := evalFile(file, env); err != nil {
+		return report(err)
+	}
+
+	changed, err := gen.Flush(out)
+	if err != nil {
+		return report(err)
+	}
+
+	return !(gen.DryRun && changed)
+}
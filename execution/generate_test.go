@@ -0,0 +1,117 @@
+package execution
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEmit(t *testing.T) {
+	tests := []struct {
+		path     string
+		content  string
+		expected string
+	}{
+		{"a.py", "print(1)", "print(1)\n"},
+		{"dir/../b.py", "print(2)\r\n\n\n", "print(2)\n"},
+		{"./c/d.py", "", "\n"},
+	}
+
+	gen := NewGenerator(t.TempDir(), false)
+	for _, tt := range tests {
+		if err := gen.Emit(tt.path, tt.content); err != nil {
+			t.Fatalf("unexpected error %s", err)
+		}
+	}
+
+	expectedPaths := []string{"a.py", "b.py", "c/d.py"}
+	paths := gen.Paths()
+	if len(paths) != len(expectedPaths) {
+		t.Fatalf("expected %d paths got %d", len(expectedPaths), len(paths))
+	}
+
+	for i, path := range expectedPaths {
+		if paths[i] != path {
+			t.Errorf("expected path %s got %s", path, paths[i])
+		}
+		if gen.files[path] != tests[i].expected {
+			t.Errorf("expected content %q got %q", tests[i].expected, gen.files[path])
+		}
+	}
+}
+
+func TestEmitErrors(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"../out.py", `emit path "../out.py" must be relative to the output directory`},
+		{"/tmp/out.py", `emit path "/tmp/out.py" must be relative to the output directory`},
+		{"a.py", `file "a.py" emitted twice with different content`},
+	}
+
+	gen := NewGenerator(t.TempDir(), false)
+	gen.Emit("a.py", "first")
+
+	for _, tt := range tests {
+		err := gen.Emit(tt.path, "second")
+		if err == nil {
+			t.Errorf("expected error %q", tt.expected)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, err.Error())
+		}
+	}
+}
+
+func TestFlush(t *testing.T) {
+	dir := t.TempDir()
+
+	gen := NewGenerator(dir, false)
+	gen.Emit("nested/a.txt", "one\ntwo")
+
+	var out bytes.Buffer
+	changed, err := gen.Flush(&out)
+	if err != nil || !changed {
+		t.Fatalf("expected files to be written. changed=%t, err=%v", changed, err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "nested", "a.txt"))
+	if err != nil {
+		t.Fatalf("file not written: %s", err)
+	}
+	if string(content) != "one\ntwo\n" {
+		t.Errorf("wrong content. got=%q", string(content))
+	}
+
+	changed, _ = gen.Flush(&out)
+	if changed {
+		t.Errorf("expected no changes on the second flush")
+	}
+}
+
+func TestFlushDryRun(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("one\nthree\n"), 0o644)
+
+	gen := NewGenerator(dir, true)
+	gen.Emit("a.txt", "one\ntwo")
+
+	var out bytes.Buffer
+	changed, err := gen.Flush(&out)
+	if err != nil || !changed {
+		t.Fatalf("expected changes. changed=%t, err=%v", changed, err)
+	}
+
+	expected := "--- a/a.txt\n+++ b/a.txt\n one\n-three\n+two\n"
+	if out.String() != expected {
+		t.Errorf("wrong diff. expected=%q, got=%q", expected, out.String())
+	}
+
+	content, _ := os.ReadFile(filepath.Join(dir, "a.txt"))
+	if string(content) != "one\nthree\n" {
+		t.Errorf("dry run modified the file. got=%q", string(content))
+	}
+}
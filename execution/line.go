@@ -0,0 +1,136 @@
+package execution
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"monkey/evaluator"
+	"monkey/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/peterh/liner"
+)
+
+const HISTORY_FILE = ".monkey_history"
+
+// Source of the lines typed in the REPL
+type lineReader interface {
+	// Returns false at the end of the input
+	readLine(prompt string) (string, bool)
+	close()
+}
+
+// Names completes the defined names besides the keywords and builtins
+func newLineReader(in io.Reader, out io.Writer, names func() []string) lineReader {
+	if isTerminal(in) && isTerminal(out) {
+		return newTerminalReader(names)
+	}
+	return &scannerReader{scanner: bufio.NewScanner(in), out: out}
+}
+
+func isTerminal(file any) bool {
+	f, ok := file.(*os.File)
+	if !ok {
+		return false
+	}
+	stat, err := f.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
+type scannerReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func (r *scannerReader) readLine(prompt string) (string, bool) {
+	fmt.Fprint(r.out, prompt)
+	if !r.scanner.Scan() {
+		return "", false
+	}
+	return r.scanner.Text(), true
+}
+
+func (r *scannerReader) close() {}
+
+// Reads from the terminal with line editing, history and tab completion
+type terminalReader struct {
+	state   *liner.State
+	history string
+}
+
+func newTerminalReader(names func() []string) *terminalReader {
+	state := liner.NewLiner()
+	state.SetWordCompleter(func(line string, pos int) (string, []string, string) {
+		return complete(line, pos, names())
+	})
+
+	r := &terminalReader{state: state}
+	if home, err := os.UserHomeDir(); err == nil {
+		r.history = filepath.Join(home, HISTORY_FILE)
+	}
+
+	if f, err := os.Open(r.history); err == nil {
+		state.ReadHistory(f)
+		f.Close()
+	}
+	return r
+}
+
+func (r *terminalReader) readLine(prompt string) (string, bool) {
+	line, err := r.state.Prompt(prompt)
+	if err != nil {
+		return "", false
+	}
+	if strings.TrimSpace(line) != "" {
+		r.state.AppendHistory(line)
+	}
+	return line, true
+}
+
+func (r *terminalReader) close() {
+	if r.history != "" {
+		if f, err := os.Create(r.history); err == nil {
+			r.state.WriteHistory(f)
+			f.Close()
+		}
+	}
+	r.state.Close()
+}
+
+// Completes the word before pos with the keywords, builtins and names given
+func complete(line string, pos int, names []string) (string, []string, string) {
+	start := pos
+	for start > 0 && (isLetter(line[start-1]) || isDigit(line[start-1])) {
+		start--
+	}
+
+	word := line[start:pos]
+	if word == "" || isDigit(word[0]) {
+		return line[:pos], nil, line[pos:]
+	}
+
+	seen := map[string]bool{}
+	completions := []string{}
+	for _, list := range [][]string{token.Keywords(), evaluator.BUILTINS, names} {
+		for _, name := range list {
+			if strings.HasPrefix(name, word) && !seen[name] {
+				seen[name] = true
+				completions = append(completions, name)
+			}
+		}
+	}
+	sort.Strings(completions)
+
+	return line[:start], completions, line[pos:]
+}
+
+func isLetter(ch byte) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch == '_'
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
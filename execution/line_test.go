@@ -0,0 +1,40 @@
+package execution
+
+import (
+	"monkey/object"
+	"strings"
+	"testing"
+)
+
+func TestComplete(t *testing.T) {
+	env := object.NewEnvironment()
+	env.Set("length", &object.Integer{Value: 1})
+	env.SmartCopy().Set("hidden", &object.Integer{Value: 2})
+
+	tests := []struct {
+		line        string
+		pos         int
+		head        string
+		completions []string
+	}{
+		{"le", 2, "", []string{"len", "length", "let"}},
+		{"echo(le)", 7, "echo(", []string{"len", "length", "let"}},
+		{"1 + mac", 7, "1 + ", []string{"macro"}},
+		{"hid", 3, "", []string{}},
+		{"1 + ", 4, "1 + ", nil},
+		{"x12", 3, "", []string{}},
+	}
+
+	for _, tt := range tests {
+		head, completions, tail := complete(tt.line, tt.pos, env.Names())
+		if head != tt.head {
+			t.Errorf("wrong head for %q. expected=%q, got=%q", tt.line, tt.head, head)
+		}
+		if tail != tt.line[tt.pos:] {
+			t.Errorf("wrong tail for %q. expected=%q, got=%q", tt.line, tt.line[tt.pos:], tail)
+		}
+		if strings.Join(completions, " ") != strings.Join(tt.completions, " ") {
+			t.Errorf("wrong completions for %q. expected=%v, got=%v", tt.line, tt.completions, completions)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package execution
+
+import (
+	"fmt"
+	"io"
+	"monkey/preprocessing"
+	"os"
+)
+
+// Prints the preprocessed file, also what was processed before an error
+func PreprocessCode(out io.Writer, file string) error {
+	text, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	output, err := preprocessing.New().Process(string(text))
+	fmt.Fprint(out, output)
+	return err
+}
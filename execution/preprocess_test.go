@@ -0,0 +1,38 @@
+package execution
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPreprocessCode(t *testing.T) {
+	tests := []struct {
+		code   string
+		output string
+		err    string
+	}{
+		{"#define a b\na\n", "b\n", ""},
+		{"#define a(\nx\n", "", "unterminated directive block #define a("},
+	}
+
+	for _, tt := range tests {
+		file := filepath.Join(t.TempDir(), "a.mky")
+		if err := os.WriteFile(file, []byte(tt.code), 0644); err != nil {
+			t.Fatalf("could not write %s: %s", file, err)
+		}
+
+		var out bytes.Buffer
+		message := ""
+		if err := PreprocessCode(&out, file); err != nil {
+			message = err.Error()
+		}
+		if message != tt.err {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.code, tt.err, message)
+		}
+		if out.String() != tt.output {
+			t.Errorf("wrong output for %q. expected=%q, got=%q", tt.code, tt.output, out.String())
+		}
+	}
+}
@@ -1,77 +1,233 @@
 package execution
 
 import (
-	"bufio"
 	"fmt"
 	"io"
+	"monkey/ast"
 	"monkey/evaluator"
 	"monkey/lexer"
 	"monkey/object"
 	"monkey/parser"
-	"monkey/token"
 	"os"
+	"strings"
 )
 
-const PROMPT = ">> "
+const (
+	PROMPT       = ">> "
+	CONTINUATION = ".. "
+)
 
-func StartREPL(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
-	env := object.NewEnvironment()
+// Diagnostics written to the standard error while running code
+type Options struct {
+	DumpTokens bool
+	DumpAst    bool
+	TraceEval  bool
+}
+
+// Reads the code from the terminal with line editing when in is one, keeping the history
+// in the home directory, errors go to errOut. Returns the ExitError when the code calls exit
+func StartREPL(in io.Reader, out io.Writer, errOut io.Writer, opts Options) error {
+	r := &repl{out: out, errOut: errOut, opts: opts}
+	r.reset()
+
+	reader := newLineReader(in, out, func() []string { return r.env.Names() })
+	defer reader.close()
 
 	for {
-		fmt.Printf(PROMPT)
-		scanned := scanner.Scan()
-		if !scanned {
-			return
+		code, ok := readCode(reader)
+		if !ok {
+			return nil
+		}
+
+		if strings.HasPrefix(strings.TrimSpace(code), ":") {
+			ok = r.command(strings.TrimSpace(code))
+		} else {
+			ok = r.print(code)
+		}
+		if !ok {
+			return r.exit
 		}
+	}
+}
 
-		line := scanner.Text()
-		l := lexer.New(line)
-		p := parser.New(l)
+// Reads lines until the code they form is complete, returns false at the end of the input
+func readCode(reader lineReader) (string, bool) {
+	code, ok := reader.readLine(PROMPT)
+	if !ok {
+		return "", false
+	}
 
-		program := p.ParseProgram()
-		if len(p.Errors()) != 0 {
-			for _, msg := range p.Errors() {
-				fmt.Println(msg)
-			}
-			continue
+	for incomplete(code) {
+		line, ok := reader.readLine(CONTINUATION)
+		if !ok {
+			break
 		}
+		code += "\n" + line
+	}
+	return code, true
+}
 
-		result := evaluator.Eval(program, env)
+// Whether the code has unclosed brackets, strings, templates or block comments
+func incomplete(code string) bool {
+	depth, comments := 0, 0
+	var quote byte
 
-		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
-			fmt.Printf("%+v\n", tok)
+	for i := 0; i < len(code); i++ {
+		ch := code[i]
+		switch {
+		case comments > 0:
+			if strings.HasPrefix(code[i:], "/*") {
+				comments++
+				i++
+			} else if strings.HasPrefix(code[i:], "*/") {
+				comments--
+				i++
+			}
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '`':
+			quote = ch
+		case strings.HasPrefix(code[i:], "/*"):
+			comments++
+			i++
+		case strings.HasPrefix(code[i:], "//"):
+			for i < len(code) && code[i] != '\n' {
+				i++
+			}
+		case ch == '(' || ch == '[' || ch == '{':
+			depth++
+		case ch == ')' || ch == ']' || ch == '}':
+			depth--
 		}
-		fmt.Println(program.String())
-		fmt.Println(result.Inspect())
 	}
+
+	return quote != 0 || comments > 0 || depth > 0
 }
 
-func RunCode(in io.Reader, out io.Writer, file string) {
+// Runs the file with the script arguments, returns a ParseError when it is not
+// valid code, a RuntimeError when it fails and an ExitError when it calls exit
+func RunCode(in io.Reader, out io.Writer, file string, args []string, opts Options) error {
 	env := object.NewEnvironment()
+	env.Set("args", argsBuiltin(args))
+	env.Set("echo", evaluator.Echo(out))
 
 	text, err := os.ReadFile(file)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
+	}
+
+	result, err := evalCode(string(text), file, env, opts)
+	if err != nil {
+		return err
 	}
 
-	l := lexer.New(string(text))
-	p := parser.New(l)
+	if result != nil {
+		fmt.Fprintln(out, result.Inspect())
+	}
+	return nil
+}
+
+func argsBuiltin(args []string) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(a ...object.Object) object.Object {
+			elements := []object.Object{}
+			for _, arg := range args {
+				elements = append(elements, &object.String{Value: arg})
+			}
+			return &object.Array{Elements: elements}
+		},
+	}
+}
+
+// Prints the error to the standard error, returns false to be used as result
+func report(err error) bool {
+	fmt.Fprintln(os.Stderr, err)
+	return false
+}
+
+func parseFile(file string) (*ast.Program, error) {
+	text, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCode(string(text), file)
+}
+
+func parseCode(text string, file string) (*ast.Program, error) {
+	p := parser.New(lexer.New(text))
 
 	program := p.ParseProgram()
 	if len(p.Errors()) != 0 {
-		for _, msg := range p.Errors() {
-			fmt.Println(msg)
-		}
-		return
+		return nil, &ParseError{File: file, Messages: p.Errors()}
+	}
+
+	return program, nil
+}
+
+// Evaluates a whole file, returns the value of its last statement
+func evalFile(file string, env *object.Environment) (object.Object, error) {
+	text, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return evalCode(string(text), file, env, Options{})
+}
+
+func evalCode(text string, file string, env *object.Environment, opts Options) (object.Object, error) {
+	if opts.DumpTokens {
+		printTokens(os.Stderr, text)
+	}
+
+	program, err := parseCode(text, file)
+	if err != nil {
+		return nil, err
 	}
 
-	result := evaluator.Eval(program, env)
+	evaluator.DefineMacros(program, env)
+	expanded, expandErr := evaluator.ExpandMacros(program, env)
+	if expandErr != nil {
+		return nil, &ParseError{File: file, Messages: []string{expandErr.Inspect()}}
+	}
+
+	if opts.DumpAst {
+		printStatements(os.Stderr, expanded.(*ast.Program))
+	}
 
-	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
-		fmt.Printf("%+v\n", tok)
+	var result object.Object
+	if opts.TraceEval {
+		result = trace(expanded.(*ast.Program), env)
+	} else {
+		result = evaluator.Eval(expanded, env)
 	}
 
-	fmt.Println(result.Inspect())
+	if result, ok := result.(*object.Error); ok {
+		return nil, runtimeError(file, result)
+	}
+
+	return result, nil
+}
+
+// Evaluates the program like evaluator.Eval printing every top level statement with its value
+func trace(program *ast.Program, env *object.Environment) object.Object {
+	var result object.Object
+	for _, stmt := range program.Statements {
+		result = evaluator.Eval(stmt, env)
+		if result == nil {
+			fmt.Fprintf(os.Stderr, "%s\n", stmt.String())
+		} else {
+			fmt.Fprintf(os.Stderr, "%s => %s\n", stmt.String(), result.Inspect())
+		}
+
+		switch result := result.(type) {
+		case *object.Return:
+			return result.Value
+		case *object.Error:
+			return result
+		}
+	}
+	return result
 }
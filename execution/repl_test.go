@@ -0,0 +1,186 @@
+package execution
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunCodeErrors(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected error
+		message  string
+	}{
+		{"let x = ;", &ParseError{}, "no prefix parse function for ; found"},
+		{"1 + true", &RuntimeError{}, "Operation + between INTEGER and BOOLEAN not implemented!"},
+		{"let f = fn() { exit(4) }; f(); 1 + true", &ExitError{}, "exit status 4"},
+	}
+
+	for _, tt := range tests {
+		file := filepath.Join(t.TempDir(), "a.mky")
+		if err := os.WriteFile(file, []byte(tt.code), 0644); err != nil {
+			t.Fatalf("could not write %s: %s", file, err)
+		}
+
+		err := RunCode(nil, io.Discard, file, nil, Options{})
+		if err == nil {
+			t.Errorf("expected error for %q", tt.code)
+			continue
+		}
+
+		switch tt.expected.(type) {
+		case *ParseError:
+			var parse *ParseError
+			if !errors.As(err, &parse) {
+				t.Errorf("expected ParseError for %q. got=%T", tt.code, err)
+			}
+		case *RuntimeError:
+			var runtime *RuntimeError
+			if !errors.As(err, &runtime) {
+				t.Errorf("expected RuntimeError for %q. got=%T", tt.code, err)
+			}
+		case *ExitError:
+			var exit *ExitError
+			if !errors.As(err, &exit) {
+				t.Errorf("expected ExitError for %q. got=%T", tt.code, err)
+			}
+		}
+
+		if !strings.HasSuffix(err.Error(), tt.message) {
+			t.Errorf("wrong message for %q. expected=%q, got=%q", tt.code, tt.message, err.Error())
+		}
+	}
+}
+
+func TestRunCodeOutput(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "a.mky")
+	if err := os.WriteFile(file, []byte(`echo("hi ", args()); let x = 1; x + 1`), 0644); err != nil {
+		t.Fatalf("could not write %s: %s", file, err)
+	}
+
+	var out bytes.Buffer
+	if err := RunCode(nil, &out, file, []string{"a"}, Options{}); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	expected := "hi [a]\n2\n"
+	if out.String() != expected {
+		t.Errorf("wrong output. expected=%q, got=%q", expected, out.String())
+	}
+}
+
+func TestStartREPL(t *testing.T) {
+	in := strings.NewReader("let x = 2\necho(x)\nlet f = fn(y) {\n\tx * y\n}\nf(3)\nlet y = ;\nexit()\nx\n")
+
+	var out, errOut bytes.Buffer
+	StartREPL(in, &out, &errOut, Options{})
+
+	expected := ">> 2\n>> 2\nnull\n>> .. .. fn(y) {\n(x * y)\n}\n>> 6\n>> >> "
+	if out.String() != expected {
+		t.Errorf("wrong output. expected=%q, got=%q", expected, out.String())
+	}
+	if errOut.String() != "no prefix parse function for ; found\n" {
+		t.Errorf("wrong errors. got=%q", errOut.String())
+	}
+}
+
+func TestIncomplete(t *testing.T) {
+	tests := []struct {
+		code       string
+		incomplete bool
+	}{
+		{"let x = 1", false},
+		{"let f = fn(x) {", true},
+		{"let f = fn(x) {\n\tx + 1\n}", false},
+		{"[1, 2,", true},
+		{"add(1,\n2", true},
+		{"1)", false},
+		{`"open`, true},
+		{`"{"`, false},
+		{"`a $b", true},
+		{"`(`", false},
+		{"let x = { // }", true},
+		{"/* a /* b */", true},
+		{"/* a /* b */ ( */ 1", false},
+	}
+
+	for _, tt := range tests {
+		if got := incomplete(tt.code); got != tt.incomplete {
+			t.Errorf("wrong result for %q. expected=%t, got=%t", tt.code, tt.incomplete, got)
+		}
+	}
+}
+
+func TestREPLCommands(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{":env", ""},
+		{"let x = 1\n:env", "1\nx = 1\n"},
+		{":type \"a\" + \"b\"", "STRING\n"},
+		{"struct Point { x, y }\n:type Point(1, 2)", "struct Point { x, y }\nPoint\n"},
+		{"struct Point { x, y }\n:type Point", "struct Point { x, y }\nSTRUCT_TYPE\n"},
+		{":type", "usage: :type expr\n"},
+		{":ast let y = 1 + 2", "*ast.LetStatement let y = (1 + 2);\n"},
+		{":tokens x + 1", "IDENT      \"x\"\n+          \"+\"\nINT        \"1\"\n"},
+		{"let x = 1\n:reset\nx", "1\nnull\n"},
+		{":load nothing.mky", "open nothing.mky: no such file or directory\n"},
+		{":nope", "unknown command :nope, try :help\n"},
+		{":help", ":env           list the names defined and their values\n:type expr     print the type of the value of the expression\n:ast expr      print the statements of the code as parsed\n:tokens expr   print the tokens of the code\n:load file     run a file keeping what it defines\n:reset         forget every name defined\n:time expr     print the value of the expression and how long it took\n:help          list the commands\n"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		StartREPL(strings.NewReader(tt.input+"\n"), &out, &out, Options{})
+
+		output := strings.ReplaceAll(out.String(), PROMPT, "")
+		if output != tt.expected {
+			t.Errorf("wrong output for %q. expected=%q, got=%q", tt.input, tt.expected, output)
+		}
+	}
+}
+
+func TestREPLLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "a.mky")
+	if err := os.WriteFile(file, []byte("let double = fn(x) { x * 2 }"), 0644); err != nil {
+		t.Fatalf("could not write %s: %s", file, err)
+	}
+
+	var out bytes.Buffer
+	StartREPL(strings.NewReader(":load "+file+"\n:time double(2)\n"), &out, &out, Options{})
+
+	output := strings.ReplaceAll(out.String(), PROMPT, "")
+	if !strings.HasPrefix(output, "4\n") || strings.Count(output, "\n") != 2 {
+		t.Errorf("wrong output. got=%q", output)
+	}
+}
+
+func TestREPLExit(t *testing.T) {
+	tests := []struct {
+		input string
+		code  int
+	}{
+		{"1 + 1", -1},
+		{"exit(3)\n1", 3},
+		{"let f = fn() { exit(2) }\n:type f()", 2},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		err := StartREPL(strings.NewReader(tt.input+"\n"), &out, &out, Options{})
+
+		var exit *ExitError
+		switch {
+		case tt.code == -1 && err != nil:
+			t.Errorf("expected no error for %q. got=%v", tt.input, err)
+		case tt.code != -1 && (!errors.As(err, &exit) || exit.Code != tt.code):
+			t.Errorf("expected exit status %d for %q. got=%v", tt.code, tt.input, err)
+		}
+	}
+}
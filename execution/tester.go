@@ -0,0 +1,75 @@
+package execution
+
+import (
+	"fmt"
+	"io"
+	"monkey/ast"
+	"monkey/evaluator"
+	"monkey/object"
+	"strings"
+)
+
+const TEST_SUFFIX = "_test.mky"
+
+// Runs the file and then every function without parameters it defines at
+// the top level whose name starts with test, returns whether all passed.
+// A test fails when it returns an error or false.
+func TestCode(out io.Writer, file string) bool {
+	program, err := parseFile(file)
+	if err != nil {
+		return report(err)
+	}
+
+	env := object.NewEnvironment()
+	env.Set("args", argsBuiltin(nil))
+	env.Set("echo", evaluator.Echo(out))
+
+	if _, err := evalFile(file, env); err != nil {
+		report(err)
+		fmt.Fprintf(out, "FAIL\t%s\n", file)
+		return false
+	}
+
+	passed := true
+	for _, name := range testNames(program) {
+		fn, ok := env.Get(name)
+		if !ok {
+			continue
+		}
+		if f, ok := fn.(*object.Function); !ok || len(f.Parameters) != 0 {
+			continue
+		}
+
+		switch result := evaluator.Call(fn).(type) {
+		case *object.Error:
+			fmt.Fprintf(out, "--- FAIL: %s\n\t%s\n", name, result.Message)
+			passed = false
+		case *object.Boolean:
+			if !result.Value {
+				fmt.Fprintf(out, "--- FAIL: %s\n\treturned false\n", name)
+				passed = false
+			}
+		}
+	}
+
+	if passed {
+		fmt.Fprintf(out, "ok\t%s\n", file)
+	} else {
+		fmt.Fprintf(out, "FAIL\t%s\n", file)
+	}
+	return passed
+}
+
+func testNames(program *ast.Program) []string {
+	names := []string{}
+	for _, stmt := range program.Statements {
+		let, ok := stmt.(*ast.LetStatement)
+		if !ok {
+			continue
+		}
+		if name, ok := let.Name.(*ast.Identifier); ok && strings.HasPrefix(name.Value, "test") {
+			names = append(names, name.Value)
+		}
+	}
+	return names
+}
@@ -0,0 +1,39 @@
+package execution
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTestCode(t *testing.T) {
+	tests := []struct {
+		code     string
+		passed   bool
+		expected string
+	}{
+		{"let test_ok = fn() { assert(1 == 1) }", true, "ok\t%s\n"},
+		{"let test_false = fn() { 1 == 2 }", false, "--- FAIL: test_false\n\treturned false\nFAIL\t%s\n"},
+		{"let test_error = fn() { assert(false, \"broken\") }", false, "--- FAIL: test_error\n\tassertion failed: broken\nFAIL\t%s\n"},
+		{"let helper = fn() { false }; let test_param = fn(x) { false }", true, "ok\t%s\n"},
+	}
+
+	for _, tt := range tests {
+		file := filepath.Join(t.TempDir(), "a_test.mky")
+		if err := os.WriteFile(file, []byte(tt.code), 0644); err != nil {
+			t.Fatalf("could not write %s: %s", file, err)
+		}
+
+		var out bytes.Buffer
+		if passed := TestCode(&out, file); passed != tt.passed {
+			t.Errorf("wrong result for %q. expected=%t, got=%t", tt.code, tt.passed, passed)
+		}
+
+		expected := fmt.Sprintf(tt.expected, file)
+		if out.String() != expected {
+			t.Errorf("wrong output for %q. expected=%q, got=%q", tt.code, expected, out.String())
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package execution
+
+import (
+	"fmt"
+	"io"
+	"monkey/ast"
+	"monkey/transpile/golang"
+	"monkey/transpile/js"
+)
+
+// Prints the file as a standalone JavaScript script, returns whether it succeeded
+func TranspileCode(out io.Writer, file string) bool {
+	program, err := parseExpanded(file)
+	if err != nil {
+		return report(err)
+	}
+
+	code, err := js.Transpile(program)
+	if err != nil {
+		return report(err)
+	}
+
+	fmt.Fprint(out, code)
+	return true
+}
+
+// Prints the file as a Go file of the package, returns whether it succeeded
+func TranspileGoCode(out io.Writer, file string, pkg string) bool {
+	program, err := parseExpanded(file)
+	if err != nil {
+		return report(err)
+	}
+
+	code, err := golang.Transpile(program, pkg)
+	if err != nil {
+		return report(err)
+	}
+
+	fmt.Fprint(out, code)
+	return true
+}
+
+func parseExpanded(file string) (*ast.Program, error) {
+	program, err := parseIncluding(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return expand(file, program)
+}
+
+// Parses the file placing the statements of the files it evaluates with
+// eval(read("file")) instead, since there is no interpreter to run them later
+func parseIncluding(file string) (*ast.Program, error) {
+	program, err := parseFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	statements := []ast.Statement{}
+	for _, stmt := range program.Statements {
+		included, ok := includedFile(stmt)
+		if !ok {
+			statements = append(statements, stmt)
+			continue
+		}
+
+		program, err := parseIncluding(included)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, program.Statements...)
+	}
+	program.Statements = statements
+
+	return program, nil
+}
+
+func includedFile(stmt ast.Statement) (string, bool) {
+	exp, ok := stmt.(*ast.ExpressionStatement)
+	if !ok {
+		return "", false
+	}
+
+	eval, ok := exp.Expression.(*ast.CallExpression)
+	if !ok || eval.Function.String() != "eval" || len(eval.Arguments) != 1 {
+		return "", false
+	}
+
+	read, ok := eval.Arguments[0].(*ast.CallExpression)
+	if !ok || read.Function.String() != "read" || len(read.Arguments) != 1 {
+		return "", false
+	}
+
+	file, ok := read.Arguments[0].(*ast.StringLiteral)
+	if !ok {
+		return "", false
+	}
+	return file.Value, true
+}
@@ -0,0 +1,393 @@
+package formatter
+
+import (
+	"bytes"
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/token"
+	"strings"
+)
+
+const (
+	_ = iota
+	LOWEST
+	OR
+	AND
+	BIT_OR
+	BIT_AND
+	EQUALS
+	LESSGREATER
+	PIPE
+	SUM
+	PRODUCT
+	PREFIX
+	CALL
+)
+
+var precedences = map[string]int{
+	token.OR:       OR,
+	token.AND:      AND,
+	token.BIT_OR:   BIT_OR,
+	token.BIT_AND:  BIT_AND,
+	token.EQ:       EQUALS,
+	token.NE:       EQUALS,
+	token.LT:       LESSGREATER,
+	token.GT:       LESSGREATER,
+	token.LE:       LESSGREATER,
+	token.GE:       LESSGREATER,
+	token.PIPE:     PIPE,
+	token.PLUS:     SUM,
+	token.MINUS:    SUM,
+	token.SLASH:    PRODUCT,
+	token.ASTERISK: PRODUCT,
+	token.PERCENT:  PRODUCT,
+}
+
+// Blocks with a single short statement stay in one line
+const INLINE_WIDTH = 60
+
+// Longer lists are broken one item per line
+const LINE_WIDTH = 80
+
+type Formatter struct {
+	indent int
+	// Lines of the source without code nor comments
+	blank map[int]bool
+}
+
+// Prints the program as canonical Monkey code
+func Format(program *ast.Program) string {
+	f := &Formatter{blank: map[int]bool{}}
+	return f.statements(program.Statements, program.Ending)
+}
+
+// Prints the program parsed with comments from source as canonical Monkey
+// code, keeping the comments and single blank lines between statements
+func FormatSource(program *ast.Program, source string) string {
+	f := &Formatter{blank: blankLines(source)}
+	return f.statements(program.Statements, program.Ending)
+}
+
+// Lines of the source without any token, comments count as tokens
+func blankLines(source string) map[int]bool {
+	used := map[int]bool{}
+	l := lexer.NewWithComments(source)
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		// Strings and comments can span lines, their literal is the source
+		for line := tok.Line; line <= tok.Line+strings.Count(tok.Literal, "\n"); line++ {
+			used[line] = true
+		}
+	}
+
+	blank := map[int]bool{}
+	for line := 1; line <= strings.Count(source, "\n"); line++ {
+		if !used[line] {
+			blank[line] = true
+		}
+	}
+	return blank
+}
+
+// Prints the statements followed by the comments ending them
+func (f *Formatter) statements(stmts []ast.Statement, ending []*ast.Comment) string {
+	type entry struct{ before, code, after string }
+
+	entries := []entry{}
+	for i, stmt := range stmts {
+		e := entry{}
+		if commented, ok := stmt.(ast.Commented); ok {
+			e.before = f.separated(commented.Comments().Leading, ast.StatementLine(stmt), i > 0)
+			for _, c := range commented.Comments().Trailing {
+				e.after += " " + c.Text()
+			}
+		}
+		e.code = f.statement(stmt)
+		entries = append(entries, e)
+	}
+
+	var out bytes.Buffer
+	indent := strings.Repeat("\t", f.indent)
+	for i, e := range entries {
+		out.WriteString(e.before)
+		out.WriteString(indent + e.code)
+		// Without it the next line would continue this expression
+		if i+1 < len(entries) && strings.ContainsAny(entries[i+1].code[:1], "([-") {
+			out.WriteString(";")
+		}
+		out.WriteString(e.after + "\n")
+	}
+	out.WriteString(f.separated(ending, 0, len(stmts) > 0))
+
+	return out.String()
+}
+
+// Prints the comments in their own lines, keeping a blank line before them or
+// the line after them when the source has one
+func (f *Formatter) separated(comments []*ast.Comment, line int, after bool) string {
+	var out bytes.Buffer
+	indent := strings.Repeat("\t", f.indent)
+
+	for _, c := range comments {
+		if after && f.blank[c.Token.Line-1] {
+			out.WriteString("\n")
+		}
+		out.WriteString(indent + c.Text() + "\n")
+		after = true
+	}
+	if after && f.blank[line-1] {
+		out.WriteString("\n")
+	}
+	return out.String()
+}
+
+func (f *Formatter) statement(stmt ast.Statement) string {
+	switch stmt := stmt.(type) {
+	case *ast.LetStatement:
+		return "let " + f.expression(stmt.Name, LOWEST) + " = " + f.expression(stmt.Value, LOWEST)
+	case *ast.ReturnStatement:
+		return "return " + f.expression(stmt.RetValue, LOWEST)
+	case *ast.ExpressionStatement:
+		return f.expression(stmt.Expression, LOWEST)
+	}
+	return stmt.String()
+}
+
+func (f *Formatter) block(block *ast.BlockStatement) string {
+	commented := len(block.Ending) > 0
+	for _, stmt := range block.Statements {
+		if c, ok := stmt.(ast.Commented); ok && len(c.Comments().Leading)+len(c.Comments().Trailing) > 0 {
+			commented = true
+		}
+	}
+
+	if len(block.Statements) == 0 && !commented {
+		return "{}"
+	}
+
+	if len(block.Statements) == 1 && !commented {
+		line := f.statement(block.Statements[0])
+		if !strings.Contains(line, "\n") && len(line) <= INLINE_WIDTH {
+			return "{ " + line + " }"
+		}
+	}
+
+	f.indent++
+	body := f.statements(block.Statements, block.Ending)
+	f.indent--
+
+	return "{\n" + body + strings.Repeat("\t", f.indent) + "}"
+}
+
+// Wraps the expression in parenthesis when it binds less than its place needs
+func (f *Formatter) expression(exp ast.Expression, precedence int) string {
+	text, own := f.format(exp)
+	if own < precedence {
+		return "(" + text + ")"
+	}
+	return text
+}
+
+// Returns the code of the expression and how strongly it binds
+func (f *Formatter) format(exp ast.Expression) (string, int) {
+	switch exp := exp.(type) {
+	case *ast.Identifier:
+		return exp.Value, CALL
+	case *ast.IntegerLiteral:
+		return exp.Token.Literal, CALL
+	case *ast.BooleanLiteral:
+		return exp.Token.Literal, CALL
+	case *ast.StringLiteral:
+		return `"` + strings.ReplaceAll(exp.Value, "\n", `\n`) + `"`, CALL
+	case *ast.TemplateString:
+		return f.template(exp), CALL
+	case *ast.PrefixExpression:
+		return exp.Operator + f.expression(exp.Right, PREFIX), PREFIX
+	case *ast.InfixExpression:
+		precedence := precedences[exp.Operator]
+		// Operators are left associative, the right side needs more
+		return f.expression(exp.Left, precedence) + " " + exp.Operator + " " + f.expression(exp.Right, precedence+1), precedence
+	case *ast.IndexExpression:
+		return f.expression(exp.Left, CALL) + "[" + f.expression(exp.Index, LOWEST) + "]", CALL
+	case *ast.MemberExpression:
+		return f.expression(exp.Left, CALL) + "." + exp.Name.Value, CALL
+	case *ast.CallExpression:
+		if exp.Piped {
+			call := f.expression(exp.Function, CALL) + f.list("(", exp.Arguments[1:], ")", nil)
+			return f.expression(exp.Arguments[0], PIPE) + " |> " + call, PIPE
+		}
+		return f.expression(exp.Function, CALL) + f.list("(", exp.Arguments, ")", nil), CALL
+	case *ast.ArrayLiteral:
+		return f.list("[", exp.Elements, "]", &exp.ItemComments), CALL
+	case *ast.HashLiteral:
+		return f.hash(exp), CALL
+	case *ast.IfExpression:
+		return f.ifExpression(exp), LOWEST
+	case *ast.MatchExpression:
+		return f.match(exp), LOWEST
+	case *ast.WhileExpression:
+		return "while " + f.expression(exp.Condition, LOWEST) + " " + f.block(exp.Body), LOWEST
+	case *ast.ForExpression:
+		return "for " + f.expression(exp.Name, LOWEST) + " in " + f.expression(exp.Iterable, LOWEST) + " " + f.block(exp.Body), LOWEST
+	case *ast.RestElement:
+		return exp.String(), PREFIX
+	case *ast.DefaultParameter:
+		return f.expression(exp.Parameter, LOWEST) + " = " + f.expression(exp.Value, LOWEST), LOWEST
+	case *ast.NamedArgument:
+		return exp.Name.Value + ": " + f.expression(exp.Value, LOWEST), LOWEST
+	case *ast.FunctionLiteral:
+		return "fn(" + f.parameters(exp.Parameters) + ") " + f.block(exp.Body), CALL
+	case *ast.AstMacroLiteral:
+		return "macro(" + identifiers(exp.Parameters) + ") " + f.block(exp.Body), CALL
+	case *ast.MacroLiteral:
+		return f.macro(exp), CALL
+	}
+	return exp.String(), LOWEST
+}
+
+// Literals have comments, nil for the arguments of calls
+func (f *Formatter) list(open string, exps []ast.Expression, close string, comments *ast.ItemComments) string {
+	return f.items(open, len(exps), close, comments, func(i int) string {
+		return f.expression(exps[i], LOWEST)
+	})
+}
+
+// Joins the items in one line unless it gets too long, a literal would span several
+// lines or has comments, then puts each item in its own line with a trailing comma
+func (f *Formatter) items(open string, count int, close string, comments *ast.ItemComments, item func(i int) string) string {
+	literal := comments != nil
+	commented := literal && comments.Any()
+
+	items := []string{}
+	for i := 0; i < count; i++ {
+		items = append(items, item(i))
+	}
+
+	inline := open + strings.Join(items, ", ") + close
+	multiline := strings.Contains(inline, "\n")
+	if !commented && (count == 0 || len(inline) <= LINE_WIDTH && !multiline || multiline && !(literal && count > 1)) {
+		return inline
+	}
+
+	// Formatted again to indent the items
+	f.indent++
+	indent := strings.Repeat("\t", f.indent)
+
+	var out bytes.Buffer
+	out.WriteString(open + "\n")
+	for i := 0; i < count; i++ {
+		var attached ast.Attached
+		if literal {
+			attached = comments.Item(i)
+		}
+		for _, c := range attached.Leading {
+			out.WriteString(indent + c.Text() + "\n")
+		}
+		out.WriteString(indent + item(i) + ",")
+		for _, c := range attached.Trailing {
+			out.WriteString(" " + c.Text())
+		}
+		out.WriteString("\n")
+	}
+	if literal {
+		for _, c := range comments.Ending {
+			out.WriteString(indent + c.Text() + "\n")
+		}
+	}
+	f.indent--
+	out.WriteString(strings.Repeat("\t", f.indent) + close)
+
+	return out.String()
+}
+
+func (f *Formatter) hash(exp *ast.HashLiteral) string {
+	return f.items("{", len(exp.Pairs), "}", &exp.ItemComments, func(i int) string {
+		return f.expression(exp.Pairs[i].Key, LOWEST) + ": " + f.expression(exp.Pairs[i].Value, LOWEST)
+	})
+}
+
+func (f *Formatter) ifExpression(exp *ast.IfExpression) string {
+	branches := []string{}
+	for _, branch := range exp.Branches {
+		branches = append(branches, "if "+f.expression(branch.Condition, LOWEST)+" "+f.block(branch.Consequence))
+	}
+
+	text := strings.Join(branches, " else ")
+	if exp.Alternative != nil {
+		text += " else " + f.block(exp.Alternative)
+	}
+	return text
+}
+
+// Each arm goes in its own line with a trailing comma
+func (f *Formatter) match(exp *ast.MatchExpression) string {
+	head := "match " + f.expression(exp.Value, LOWEST) + " "
+	if len(exp.Arms) == 0 {
+		return head + "{}"
+	}
+
+	f.indent++
+	indent := strings.Repeat("\t", f.indent)
+
+	var out bytes.Buffer
+	out.WriteString(head + "{\n")
+	for _, arm := range exp.Arms {
+		pattern := f.expression(arm.Pattern, LOWEST)
+		if arm.Guard != nil {
+			pattern += " if " + f.expression(arm.Guard, LOWEST)
+		}
+		out.WriteString(indent + pattern + " => " + f.expression(arm.Value, LOWEST) + ",\n")
+	}
+	f.indent--
+	out.WriteString(strings.Repeat("\t", f.indent) + "}")
+
+	return out.String()
+}
+
+func (f *Formatter) template(exp *ast.TemplateString) string {
+	var out bytes.Buffer
+
+	out.WriteString("`")
+	for _, e := range exp.Elements {
+		switch e := e.(type) {
+		case *ast.StringLiteral:
+			out.WriteString(e.Value)
+		default:
+			out.WriteString("$" + e.String())
+		}
+	}
+	out.WriteString("`")
+
+	return out.String()
+}
+
+func (f *Formatter) macro(exp *ast.MacroLiteral) string {
+	params := []string{}
+	for i, p := range exp.Parameters {
+		params = append(params, p.Value+": "+f.expression(exp.Pattern[i], LOWEST))
+	}
+
+	body := f.template(exp.Body)
+	if !strings.Contains(body, "\n") {
+		return "macro(" + strings.Join(params, ", ") + ") { " + body + " }"
+	}
+
+	indent := strings.Repeat("\t", f.indent)
+	return "macro(" + strings.Join(params, ", ") + ") {\n" + indent + "\t" + body + "\n" + indent + "}"
+}
+
+// Parameters stay in one line, patterns included
+func (f *Formatter) parameters(params []ast.Expression) string {
+	formatted := []string{}
+	for _, p := range params {
+		formatted = append(formatted, f.expression(p, LOWEST))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func identifiers(idents []*ast.Identifier) string {
+	names := []string{}
+	for _, i := range idents {
+		names = append(names, i.Value)
+	}
+	return strings.Join(names, ", ")
+}
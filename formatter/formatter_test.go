@@ -0,0 +1,159 @@
+package formatter
+
+import (
+	"monkey/lexer"
+	"monkey/parser"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let  x=5;let y = x", "let x = 5\nlet y = x\n"},
+		{"(1 + 2) * 3 - (4 - 5)", "(1 + 2) * 3 - (4 - 5)\n"},
+		{"1 - 2 - 3; -a[1]; (-a)[1]", "1 - 2 - 3;\n-a[1];\n(-a)[1]\n"},
+		{"a\n(b)", "a(b)\n"},
+		{"a||b&&(c||d)", "a || b && (c || d)\n"},
+		{"a;\n[b]", "a;\n[b]\n"},
+		{"fn(x){x*x}", "fn(x) { x * x }\n"},
+		{"fn(x) { let y = x; y }", "fn(x) {\n\tlet y = x\n\ty\n}\n"},
+		{"if a { 1 } else if (b) { 2 } else { 3 }", "if a { 1 } else if b { 2 } else { 3 }\n"},
+		{"if (a > b) { a } else { if b { let c = 1; c } }", "if a > b { a } else {\n\tif b {\n\t\tlet c = 1\n\t\tc\n\t}\n}\n"},
+		{"match x {[a, ...b] if a>0=>b, _=>[]}", "match x {\n\t[a, ...b] if a > 0 => b,\n\t_ => [],\n}\n"},
+		{"match x {}", "match x {}\n"},
+		{"while (i<3) {let i=i+1; if i==2 {continue}; i}", "while i < 3 {\n\tlet i = i + 1\n\tif i == 2 { continue }\n\ti\n}\n"},
+		{"for [k,v] in pairs(h) { if v {break} }; for c in \"ab\" {c}", "for [k, v] in pairs(h) { if v { break } }\nfor c in \"ab\" { c }\n"},
+		{"(a+b)|>f(1)|>g(); (x |> f()) + 1; x |> (y |> f())", "a + b |> f(1) |> g();\n(x |> f()) + 1\nx |> f(y)\n"},
+		{"(-a).b.c( d )[0].e; (a+b).len()", "(-a).b.c(d)[0].e;\n(a + b).len()\n"},
+		{"struct P {a,\nb,}; struct Q {}", "struct P { a, b }\nstruct Q {}\n"},
+		{"fn(x, y=x+1, ...rest){x}(1, y:2)", "fn(x, y = x + 1, ...rest) { x }(1, y: 2)\n"},
+		{"let [a,...b]=c; fn([x], {\"k\":y}){x}", "let [a, ...b] = c\nfn([x], {\"k\": y}) { x }\n"},
+		{`{"b": [1, 2], "a": {}}`, "{\"b\": [1, 2], \"a\": {}}\n"},
+		{"\"a\\nb\"", "\"a\\nb\"\n"},
+		{"macro(x: int, y: \"=\") { `$x + $y` }", "macro(x: int, y: \"=\") { `$x + $y` }\n"},
+		{"macro(a, b) { quote(unquote(a) + unquote(b)) }", "macro(a, b) { quote(unquote(a) + unquote(b)) }\n"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("could not parse %q: %v", tt.input, p.Errors())
+		}
+
+		formatted := Format(program)
+		if formatted != tt.expected {
+			t.Errorf("wrong format for %q. expected=%q, got=%q", tt.input, tt.expected, formatted)
+		}
+
+		again := Format(parser.New(lexer.New(formatted)).ParseProgram())
+		if again != formatted {
+			t.Errorf("formatting %q again changed it. got=%q", formatted, again)
+		}
+	}
+}
+
+func TestFormatSource(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"// first\nlet x = 1 // one\n\n\n\n// two\nx", "// first\nlet x = 1 // one\n\n// two\nx\n"},
+		{"let f = fn(x) { // start\n\tx\n\n\t// end\n}", "let f = fn(x) {\n\t// start\n\tx\n\n\t// end\n}\n"},
+		{"if x {\n\t// nothing\n}", "if x {\n\t// nothing\n}\n"},
+		{"a // after a\n(b)", "a(b) // after a\n"},
+		{"a; b; // after b\n[c]", "a\nb; // after b\n[c]\n"},
+		{"let s = \"// not a comment\"", "let s = \"// not a comment\"\n"},
+		{"x\n// the end", "x\n// the end\n"},
+		{"let s = \"a\n\nb\"\nx", "let s = \"a\\n\\nb\"\nx\n"},
+		{"let h = {\n\t// first\n\t\"a\": 1,\n\t\"b\": 2 // two\n}\nh", "let h = {\n\t// first\n\t\"a\": 1,\n\t\"b\": 2, // two\n}\nh\n"},
+		{"let h = {\"a\": 1, // one\n\t// end\n}", "let h = {\n\t\"a\": 1, // one\n\t// end\n}\n"},
+		{"let a = [\n\t1,\n\t// two\n\t2,\n]", "let a = [\n\t1,\n\t// two\n\t2,\n]\n"},
+		{"let a = [ // none\n]", "let a = [\n\t// none\n]\n"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.NewWithComments(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("could not parse %q: %v", tt.input, p.Errors())
+		}
+
+		formatted := FormatSource(program, tt.input)
+		if formatted != tt.expected {
+			t.Errorf("wrong format for %q. expected=%q, got=%q", tt.input, tt.expected, formatted)
+		}
+	}
+}
+
+func TestFormatLongLists(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			`let names = ["Alice", "Bob", "Carol", "Dave", "Erin", "Frank", "Grace", "Heidi", "Ivan", "Judy", "Mallory"]`,
+			"let names = [\n\t\"Alice\",\n\t\"Bob\",\n\t\"Carol\",\n\t\"Dave\",\n\t\"Erin\",\n\t\"Frank\",\n\t\"Grace\",\n\t\"Heidi\",\n\t\"Ivan\",\n\t\"Judy\",\n\t\"Mallory\",\n]\n",
+		},
+		{
+			`echo("a very long message that goes on", "and on and on", "until it does not fit in a line")`,
+			"echo(\n\t\"a very long message that goes on\",\n\t\"and on and on\",\n\t\"until it does not fit in a line\",\n)\n",
+		},
+		{
+			"map(arr, fn(x) { let y = x; y })",
+			"map(arr, fn(x) {\n\tlet y = x\n\ty\n})\n",
+		},
+		{
+			`{"a": fn(x) { let y = x; y }, "b": 2}`,
+			"{\n\t\"a\": fn(x) {\n\t\tlet y = x\n\t\ty\n\t},\n\t\"b\": 2,\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		formatted := Format(parser.New(lexer.New(tt.input)).ParseProgram())
+		if formatted != tt.expected {
+			t.Errorf("wrong format for %q. expected=%q, got=%q", tt.input, tt.expected, formatted)
+		}
+
+		again := Format(parser.New(lexer.New(formatted)).ParseProgram())
+		if again != formatted {
+			t.Errorf("formatting %q again changed it. got=%q", formatted, again)
+		}
+	}
+}
+
+func TestFormatExamples(t *testing.T) {
+	files, err := filepath.Glob("../examples/*.mky")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no examples found: %v", err)
+	}
+
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("could not read %s: %s", file, err)
+		}
+
+		formatted := FormatSource(parser.New(lexer.NewWithComments(string(source))).ParseProgram(), string(source))
+
+		p := parser.New(lexer.NewWithComments(formatted))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Errorf("formatted %s does not parse: %v", file, p.Errors())
+			continue
+		}
+
+		if strings.Count(formatted, "//") != strings.Count(string(source), "//") {
+			t.Errorf("formatting %s lost comments", file)
+		}
+
+		if again := FormatSource(program, formatted); again != formatted {
+			t.Errorf("formatting %s is not idempotent. first=%q, second=%q", file, formatted, again)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package lexer
 
 import (
+	"fmt"
 	"monkey/token"
+	"strings"
 )
 
 type Lexer struct {
@@ -11,9 +13,12 @@ type Lexer struct {
 	ch           byte
 
 	context token.TokenType
-	Line    int
-	Col     int
-	column  int
+	// Whether comments are tokens instead of whitespace
+	comments bool
+	errors   []string
+	Line     int
+	Col      int
+	column   int
 }
 
 func New(input string) *Lexer {
@@ -23,6 +28,13 @@ func New(input string) *Lexer {
 	return l
 }
 
+// A lexer returning the comments as COMMENT tokens
+func NewWithComments(input string) *Lexer {
+	l := New(input)
+	l.comments = true
+	return l
+}
+
 func (l *Lexer) readChar() {
 	l.ch = l.peekChar()
 	l.position = l.readPosition
@@ -55,6 +67,14 @@ func (l *Lexer) preEqual(pre token.TokenType, alone token.TokenType) token.Token
 	}
 }
 
+func (l *Lexer) doubled(double token.TokenType, alone token.TokenType) token.Token {
+	if l.peekChar() == l.ch {
+		l.readChar()
+		return token.Token{Type: double, Literal: string(l.ch) + string(l.ch)}
+	}
+	return newToken(alone, l.ch)
+}
+
 func (l *Lexer) readTemplateIdent() token.Token {
 	var tok token.Token
 
@@ -75,15 +95,25 @@ func (l *Lexer) readTemplateIdent() token.Token {
 	return newToken(token.ILLEGAL, l.ch)
 }
 
+// Returns the next token with the line where it starts
 func (l *Lexer) NextToken() token.Token {
-	var tok token.Token
-
 	if l.context == token.EOF {
 		l.skipWhitespace()
 	}
 
+	line := l.Line + 1
+	tok := l.readToken()
+	tok.Line = line
+	return tok
+}
+
+func (l *Lexer) readToken() token.Token {
+	var tok token.Token
+
 	if l.context == token.TEMPLATE {
-		if l.ch == '$' {
+		if l.ch == 0 {
+			tok.Type = token.EOF
+		} else if l.ch == '$' {
 			return l.readTemplateIdent()
 		} else {
 			tok.Type = token.TEMPLATE
@@ -92,7 +122,20 @@ func (l *Lexer) NextToken() token.Token {
 	} else {
 		switch l.ch {
 		case '=':
-			tok = l.preEqual(token.EQ, token.ASSIGN)
+			if l.peekChar() == '>' {
+				l.readChar()
+				tok = token.Token{Type: token.ARROW, Literal: "=>"}
+			} else {
+				tok = l.preEqual(token.EQ, token.ASSIGN)
+			}
+		case '.':
+			if strings.HasPrefix(l.input[l.position:], token.ELLIPSIS) {
+				l.readChar()
+				l.readChar()
+				tok = token.Token{Type: token.ELLIPSIS, Literal: token.ELLIPSIS}
+			} else {
+				tok = newToken(token.DOT, l.ch)
+			}
 		case '!':
 			tok = l.preEqual(token.NE, token.BANG)
 		case '<':
@@ -122,15 +165,34 @@ func (l *Lexer) NextToken() token.Token {
 		case '*':
 			tok = newToken(token.ASTERISK, l.ch)
 		case '/':
+			if l.isDoc() {
+				tok.Type = token.DOC
+				tok.Literal = l.readComment()
+				return tok
+			}
+			if l.comments && l.isComment() {
+				tok.Type = token.COMMENT
+				if l.peekChar() == '*' {
+					tok.Literal = l.readBlockComment()
+				} else {
+					tok.Literal = l.readComment()
+				}
+				return tok
+			}
 			tok = newToken(token.SLASH, l.ch)
 		case '%':
 			tok = newToken(token.PERCENT, l.ch)
 		case ':':
 			tok = newToken(token.COLON, l.ch)
 		case '&':
-			tok = newToken(token.AND, l.ch)
+			tok = l.doubled(token.AND, token.BIT_AND)
 		case '|':
-			tok = newToken(token.OR, l.ch)
+			if l.peekChar() == '>' {
+				l.readChar()
+				tok = token.Token{Type: token.PIPE, Literal: "|>"}
+			} else {
+				tok = l.doubled(token.OR, token.BIT_OR)
+			}
 		case '"':
 			tok.Type = token.STRING
 			tok.Literal = l.readString()
@@ -207,23 +269,68 @@ func (l *Lexer) readNumber() string {
 	return l.input[position:l.position]
 }
 
-func (l *Lexer) skipComments() {
-	// Handle comments lol
-	if l.ch == '/' && l.peekChar() == '/' {
-		for l.ch != '\n' && l.ch != 0 {
+func (l *Lexer) readComment() string {
+	position := l.position
+	for l.ch != '\n' && l.ch != 0 {
+		l.readChar()
+	}
+	return strings.TrimRight(l.input[position:l.position], "\r")
+}
+
+// Reads a /* */ comment, which can contain other ones
+func (l *Lexer) readBlockComment() string {
+	position := l.position
+	line := l.Line + 1
+
+	depth := 0
+	for l.ch != 0 {
+		if l.ch == '/' && l.peekChar() == '*' {
+			depth++
 			l.readChar()
+		} else if l.ch == '*' && l.peekChar() == '/' {
+			depth--
+			l.readChar()
+			if depth == 0 {
+				l.readChar()
+				return l.input[position:l.position]
+			}
 		}
+		l.readChar()
 	}
+
+	l.errors = append(l.errors, fmt.Sprintf("Error at line %d. block comment not terminated", line))
+	return l.input[position:l.position]
+}
+
+// Whether a /// comment documenting the next binding starts
+func (l *Lexer) isDoc() bool {
+	return strings.HasPrefix(l.input[l.position:], "///") && !strings.HasPrefix(l.input[l.position:], "////")
+}
+
+func (l *Lexer) isComment() bool {
+	return l.ch == '/' && (l.peekChar() == '/' && !l.isDoc() || l.peekChar() == '*')
 }
 
 func (l *Lexer) skipWhitespace() {
-	l.skipComments()
-	for l.ch == ' ' || l.ch == '\n' || l.ch == '\t' || l.ch == '\r' {
-		l.readChar()
-		l.skipComments()
+	for {
+		switch {
+		case l.ch == ' ' || l.ch == '\n' || l.ch == '\t' || l.ch == '\r':
+			l.readChar()
+		case !l.comments && l.isComment() && l.peekChar() == '*':
+			l.readBlockComment()
+		case !l.comments && l.isComment():
+			l.readComment()
+		default:
+			return
+		}
 	}
 }
 
+// Problems found reading the input, like comments not terminated
+func (l *Lexer) Errors() []string {
+	return l.errors
+}
+
 func isLetter(ch byte) bool {
 	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch == '_'
 }
@@ -2,6 +2,7 @@ package lexer
 
 import (
 	"monkey/token"
+	"strings"
 	"testing"
 )
 
@@ -14,7 +15,7 @@ func TestNextToken(t *testing.T) {
     };
 
     let result = add(five, ten);
-    !-/*5;
+    !-/ *5;
     5 < 10 > 5;
     if (5 < 10) {
         return true;
@@ -33,7 +34,11 @@ func TestNextToken(t *testing.T) {
 
 	5 >= 10 <= 5
 	10 % 10
-	true & true | false
+	true & true | false && true || 1
+	match x { [a, ...b] => a } ..
+	x |> f() | y
+	a.len()
+	while for x in y break continue
 	` +
 		"`this is a $literal template\\n string`" +
 		"`at $end`" +
@@ -156,10 +161,49 @@ func TestNextToken(t *testing.T) {
 		{token.INT, "10"},
 
 		{token.TRUE, "true"},
-		{token.AND, "&"},
+		{token.BIT_AND, "&"},
 		{token.TRUE, "true"},
-		{token.OR, "|"},
+		{token.BIT_OR, "|"},
 		{token.FALSE, "false"},
+		{token.AND, "&&"},
+		{token.TRUE, "true"},
+		{token.OR, "||"},
+		{token.INT, "1"},
+
+		{token.MATCH, "match"},
+		{token.IDENT, "x"},
+		{token.LBRACE, "{"},
+		{token.LBRACKET, "["},
+		{token.IDENT, "a"},
+		{token.COMMA, ","},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "b"},
+		{token.RBRACKET, "]"},
+		{token.ARROW, "=>"},
+		{token.IDENT, "a"},
+		{token.RBRACE, "}"},
+		{token.DOT, "."},
+		{token.DOT, "."},
+
+		{token.IDENT, "x"},
+		{token.PIPE, "|>"},
+		{token.IDENT, "f"},
+		{token.LPAREN, "("},
+		{token.RPAREN, ")"},
+		{token.BIT_OR, "|"},
+		{token.IDENT, "y"},
+		{token.IDENT, "a"},
+		{token.DOT, "."},
+		{token.IDENT, "len"},
+		{token.LPAREN, "("},
+		{token.RPAREN, ")"},
+		{token.WHILE, "while"},
+		{token.FOR, "for"},
+		{token.IDENT, "x"},
+		{token.IN, "in"},
+		{token.IDENT, "y"},
+		{token.BREAK, "break"},
+		{token.CONTINUE, "continue"},
 
 		{token.TEMPLATE, `this is a `},
 		{token.IDENT, `literal`},
@@ -195,3 +239,91 @@ func TestNextToken(t *testing.T) {
 		}
 	}
 }
+
+func TestTokenLines(t *testing.T) {
+	input := "let x = 1;\n// comment\n\nlet s = \"a\nb\"; x\n"
+
+	expected := []int{1, 1, 1, 1, 1, 4, 4, 4, 4, 5, 5, 6}
+
+	l := New(input)
+	for i, line := range expected {
+		tok := l.NextToken()
+		if tok.Line != line {
+			t.Errorf("tests[%d] - line of %q wrong. expected=%d, got=%d", i, tok.Literal, line, tok.Line)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := "// first\nlet x = 1 / 2; // half\r\n// last"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.COMMENT, "// first"},
+		{token.LET, "let"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.INT, "1"},
+		{token.SLASH, "/"},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.COMMENT, "// half"},
+		{token.COMMENT, "// last"},
+		{token.EOF, ""},
+	}
+
+	l := NewWithComments(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Errorf("tests[%d] - wrong token. expected=%s %q, got=%s %q", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+
+	l = New(input)
+	if tok := l.NextToken(); tok.Type != token.LET {
+		t.Errorf("comments are not skipped by default. got=%s %q", tok.Type, tok.Literal)
+	}
+}
+
+func TestBlockComments(t *testing.T) {
+	tests := []struct {
+		input    string
+		comments bool
+		expected []string
+		errors   int
+	}{
+		{"1 /* one */ + 2", false, []string{"1", "+", "2", ""}, 0},
+		{"1 /* outer /* inner */ still outer */ + 2", false, []string{"1", "+", "2", ""}, 0},
+		{"1 /* not\nclosed /* */", false, []string{"1", ""}, 1},
+		{"1 / 2 * 3", false, []string{"1", "/", "2", "*", "3", ""}, 0},
+		{"1 /* a /* b */ */ + 2", true, []string{"1", "/* a /* b */ */", "+", "2", ""}, 0},
+		{"/// doc\nlet", false, []string{"/// doc", "let", ""}, 0},
+		{"//// not doc\nlet", false, []string{"let", ""}, 0},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		if tt.comments {
+			l = NewWithComments(tt.input)
+		}
+
+		literals := []string{}
+		for {
+			tok := l.NextToken()
+			literals = append(literals, tok.Literal)
+			if tok.Type == token.EOF {
+				break
+			}
+		}
+
+		if strings.Join(literals, "|") != strings.Join(tt.expected, "|") {
+			t.Errorf("wrong tokens for %q. expected=%q, got=%q", tt.input, tt.expected, literals)
+		}
+		if len(l.Errors()) != tt.errors {
+			t.Errorf("wrong errors for %q. expected %d, got=%q", tt.input, tt.errors, l.Errors())
+		}
+	}
+}
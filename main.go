@@ -1,56 +1,208 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"monkey/execution"
 	"os"
 	"os/user"
+	"path/filepath"
+	"strings"
 )
 
-const EXT = ".mky"
+const (
+	EXT     = ".mky"
+	VERSION = "0.1.0"
+)
+
+// Exit statuses, programs calling exit choose their own
+const (
+	OK      = 0
+	FAILURE = 1
+	USAGE   = 2
+	SYNTAX  = 3
+)
+
+type command struct {
+	name        string
+	usage       string
+	description string
+	run         func(args []string) int
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"run", "run [--dump-tokens] [--dump-ast] [--trace-eval] [file.mky | dir] [arguments]", "run a program, or every program of a directory", run},
+		{"repl", "repl [--dump-tokens] [--dump-ast] [--trace-eval]", "start the interactive interpreter", repl},
+		{"fmt", "fmt [--check] file.mky...", "print programs formatted", format},
+		{"check", "check file.mky...", "parse programs and expand their macros without running them", check},
+		{"test", "test [file.mky | dir]...", "run the test functions of the *_test.mky files", test},
+		{"tokens", "tokens file.mky", "print the tokens of a program", tokens},
+		{"ast", "ast file.mky", "print the statements of a program as parsed", printAst},
+		{"doc", "doc file.mky...", "print the top level bindings of programs with their /// comments", doc},
+		{"gen", "gen [--out dir] [--dry-run] file.mky", "run a program writing the files it emits", generate},
+		{"preprocess", "preprocess file", "run the preprocessor on a file", preprocess},
+		{"to-js", "to-js file.mky", "print a program as JavaScript", toJs},
+		{"to-go", "to-go [--package name] file.mky", "print a program as Go", toGo},
+		{"version", "version", "print the Monkey version", version},
+		{"help", "help [command]", "show the help of a command", help},
+	}
+}
+
+// Lists the commands, to the standard error when the command line is wrong
+func usage(out io.Writer) {
+	fmt.Fprintln(out, "Monkey is a programming language, because we type like monkeys")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Usage:")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "\tmonkey <command> [arguments]")
+	fmt.Fprintln(out, "\tmonkey file.mky [arguments]   same as monkey run")
+	fmt.Fprintln(out, "\tmonkey                        same as monkey repl")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "The commands are:")
+	fmt.Fprintln(out)
+	for _, c := range commands {
+		fmt.Fprintf(out, "\t%-12s %s\n", c.name, c.description)
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Use \"monkey help <command>\" for more information about a command.")
+}
+
+func find(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
 
-func runMultipleFiles(directory string) {
+// Flags of a command, -h and --help show its usage
+func flags(name string) *flag.FlagSet {
+	c, _ := find(name)
+	set := flag.NewFlagSet(name, flag.ExitOnError)
+	set.Usage = func() {
+		fmt.Fprintf(set.Output(), "usage: monkey %s\n\n%s\n", c.usage, c.description)
+		set.PrintDefaults()
+	}
+	return set
+}
+
+func usageError(name string) int {
+	c, _ := find(name)
+	fmt.Fprintf(os.Stderr, "usage: monkey %s\n", c.usage)
+	return USAGE
+}
+
+func status(ok bool) int {
+	if ok {
+		return OK
+	}
+	return FAILURE
+}
+
+// Prints the error to the standard error, returns the status it exits with
+func errorStatus(err error) int {
+	var exit *execution.ExitError
+	var parse *execution.ParseError
+
+	switch {
+	case err == nil:
+		return OK
+	case errors.As(err, &exit):
+		return exit.Code
+	case errors.As(err, &parse):
+		fmt.Fprintln(os.Stderr, err)
+		return SYNTAX
+	default:
+		fmt.Fprintln(os.Stderr, err)
+		return FAILURE
+	}
+}
+
+func isProgram(name string) bool {
+	return len(name) > len(EXT) && strings.HasSuffix(name, EXT)
+}
+
+// Runs every program of the directory even when some fail, stops at the first
+// calling exit. Returns the status of the last failing program.
+func runMultipleFiles(directory string, args []string, opts execution.Options) int {
 	dir, err := os.ReadDir(directory)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return errorStatus(err)
 	}
 
+	result := OK
 	for _, file := range dir {
 		name := file.Name()
 		if name[0] == '.' {
 			continue
 		}
 
+		path := filepath.Join(directory, name)
+
 		if file.IsDir() {
-			runMultipleFiles(name)
+			if status := runMultipleFiles(path, args, opts); status != OK {
+				result = status
+			}
 			continue
 		}
 
-		if len(name) > len(EXT) && name[len(name)-4:] == EXT {
-			execution.RunCode(os.Stdin, os.Stdout, directory+"/"+name)
+		if !isProgram(name) {
+			continue
+		}
+
+		err := execution.RunCode(os.Stdin, os.Stdout, path, args, opts)
+		var exit *execution.ExitError
+		if errors.As(err, &exit) {
+			os.Exit(exit.Code)
+		}
+		if status := errorStatus(err); status != OK {
+			result = status
 		}
 	}
+	return result
 }
 
-func main() {
-	if len(os.Args) > 1 {
-		filepath := os.Args[1]
+// Flags of the commands running code
+func options(set *flag.FlagSet) *execution.Options {
+	opts := &execution.Options{}
+	set.BoolVar(&opts.DumpTokens, "dump-tokens", false, "print the tokens of the code to the standard error")
+	set.BoolVar(&opts.DumpAst, "dump-ast", false, "print the statements of the code as parsed to the standard error")
+	set.BoolVar(&opts.TraceEval, "trace-eval", false, "print every top level statement with its value to the standard error")
+	return opts
+}
 
-		stat, err := os.Stat(filepath)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
+func run(args []string) int {
+	set := flags("run")
+	opts := options(set)
+	set.Parse(args)
 
-		if stat.IsDir() {
-			runMultipleFiles(stat.Name())
-		} else {
-			execution.RunCode(os.Stdin, os.Stdout, filepath)
-		}
-		return
+	if set.NArg() < 1 {
+		return usageError("run")
+	}
+
+	path := set.Arg(0)
+	stat, err := os.Stat(path)
+	if err != nil {
+		return errorStatus(err)
 	}
 
+	if stat.IsDir() {
+		return runMultipleFiles(path, set.Args()[1:], *opts)
+	}
+	return errorStatus(execution.RunCode(os.Stdin, os.Stdout, path, set.Args()[1:], *opts))
+}
+
+func repl(args []string) int {
+	set := flags("repl")
+	opts := options(set)
+	set.Parse(args)
+
 	user, err := user.Current()
 	if err != nil {
 		panic(err)
@@ -59,5 +211,206 @@ func main() {
 	fmt.Printf("Hi %s! This is the Monkey Programming Language\n", user.Username)
 	fmt.Printf("Feel free to type in commands\n")
 
-	execution.StartREPL(os.Stdin, os.Stdout)
+	return errorStatus(execution.StartREPL(os.Stdin, os.Stdout, os.Stderr, *opts))
+}
+
+// Runs the action on every file given, returns whether it succeeded for all of them
+func eachFile(name string, args []string, action func(file string) bool) int {
+	set := flags(name)
+	set.Parse(args)
+
+	if set.NArg() < 1 {
+		return usageError(name)
+	}
+
+	ok := true
+	for _, file := range set.Args() {
+		ok = action(file) && ok
+	}
+	return status(ok)
+}
+
+func format(args []string) int {
+	set := flags("fmt")
+	check := set.Bool("check", false, "only list the files whose formatting differs, failing if any")
+	set.Parse(args)
+
+	if set.NArg() < 1 {
+		return usageError("fmt")
+	}
+
+	ok := true
+	for _, file := range set.Args() {
+		ok = execution.FormatCode(os.Stdout, file, *check) && ok
+	}
+	return status(ok)
+}
+
+func check(args []string) int {
+	set := flags("check")
+	set.Parse(args)
+
+	if set.NArg() < 1 {
+		return usageError("check")
+	}
+
+	result := OK
+	for _, file := range set.Args() {
+		if status := errorStatus(execution.CheckCode(file)); status != OK {
+			result = status
+		}
+	}
+	return result
+}
+
+func tokens(args []string) int {
+	return eachFile("tokens", args, func(file string) bool {
+		return execution.PrintTokens(os.Stdout, file)
+	})
+}
+
+func printAst(args []string) int {
+	return eachFile("ast", args, func(file string) bool {
+		return execution.PrintAst(os.Stdout, file)
+	})
+}
+
+func doc(args []string) int {
+	return eachFile("doc", args, func(file string) bool {
+		return execution.PrintDocs(os.Stdout, file)
+	})
+}
+
+func test(args []string) int {
+	set := flags("test")
+	set.Parse(args)
+
+	paths := set.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	files := []string{}
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(file string, entry os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() && file != path && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if !entry.IsDir() && (file == path || strings.HasSuffix(file, execution.TEST_SUFFIX)) {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return errorStatus(err)
+		}
+	}
+
+	if len(files) == 0 {
+		fmt.Println("no test files found")
+		return OK
+	}
+
+	ok := true
+	for _, file := range files {
+		ok = execution.TestCode(os.Stdout, file) && ok
+	}
+	return status(ok)
+}
+
+func generate(args []string) int {
+	set := flags("gen")
+	out := set.String("out", ".", "directory where the emitted files are written")
+	dryRun := set.Bool("dry-run", false, "show the differences with the existing files instead of writing them")
+	set.Parse(args)
+
+	if set.NArg() != 1 {
+		return usageError("gen")
+	}
+
+	gen := execution.NewGenerator(*out, *dryRun)
+	return status(execution.GenerateCode(os.Stdout, set.Arg(0), gen))
+}
+
+func preprocess(args []string) int {
+	set := flags("preprocess")
+	set.Parse(args)
+
+	if set.NArg() != 1 {
+		return usageError("preprocess")
+	}
+
+	return errorStatus(execution.PreprocessCode(os.Stdout, set.Arg(0)))
+}
+
+func toJs(args []string) int {
+	set := flags("to-js")
+	set.Parse(args)
+
+	if set.NArg() != 1 {
+		return usageError("to-js")
+	}
+
+	return status(execution.TranspileCode(os.Stdout, set.Arg(0)))
+}
+
+func toGo(args []string) int {
+	set := flags("to-go")
+	pkg := set.String("package", "main", "package of the generated file, main adds a main function running it")
+	set.Parse(args)
+
+	if set.NArg() != 1 {
+		return usageError("to-go")
+	}
+
+	return status(execution.TranspileGoCode(os.Stdout, set.Arg(0), *pkg))
+}
+
+func version(args []string) int {
+	fmt.Printf("monkey %s\n", VERSION)
+	return OK
+}
+
+func help(args []string) int {
+	if len(args) == 0 {
+		usage(os.Stdout)
+		return OK
+	}
+
+	if _, ok := find(args[0]); !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", args[0])
+		return USAGE
+	}
+
+	flags(args[0]).Usage()
+	return OK
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		os.Exit(repl(nil))
+	}
+
+	name := os.Args[1]
+	switch name {
+	case "-h", "-help", "--help":
+		os.Exit(help(nil))
+	case "-v", "-version", "--version":
+		os.Exit(version(nil))
+	}
+
+	if c, ok := find(name); ok {
+		os.Exit(c.run(os.Args[2:]))
+	}
+
+	if _, err := os.Stat(name); err != nil {
+		fmt.Fprintf(os.Stderr, "unknown command or file %q\n\n", name)
+		usage(os.Stderr)
+		os.Exit(USAGE)
+	}
+
+	os.Exit(run(os.Args[1:]))
 }
@@ -1,5 +1,7 @@
 package object
 
+import "sort"
+
 func NewEnvironment() *Environment {
 	s := make(map[string]Object)
 	return &Environment{store: s}
@@ -28,3 +30,19 @@ func (e *Environment) SmartCopy() *Environment {
 	env.outer = e
 	return env
 }
+
+// The names bound in the environment and its outer ones, sorted
+func (e *Environment) Names() []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for env := e; env != nil; env = env.outer {
+		for name := range env.store {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
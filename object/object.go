@@ -5,21 +5,29 @@ import (
 	"fmt"
 	"hash/fnv"
 	"monkey/ast"
+	"strconv"
 	"strings"
 )
 
 const (
-	INTEGER  = "INTEGER"
-	BOOLEAN  = "BOOLEAN"
-	RETURN   = "RETURN"
-	NULL     = "NULL"
-	ERROR    = "ERROR"
-	FUNCTION = "FUNCTION"
-	MACRO    = "MACRO"
-	STRING   = "STRING"
-	BUILTIN  = "BUILTIN"
-	ARRAY    = "ARRAY"
-	HASH     = "HASH"
+	INTEGER   = "INTEGER"
+	BOOLEAN   = "BOOLEAN"
+	RETURN    = "RETURN"
+	BREAK     = "BREAK"
+	CONTINUE  = "CONTINUE"
+	NULL      = "NULL"
+	ERROR     = "ERROR"
+	FUNCTION  = "FUNCTION"
+	MACRO     = "MACRO"
+	AST_MACRO = "AST_MACRO"
+	QUOTE     = "QUOTE"
+	STRING    = "STRING"
+	BUILTIN   = "BUILTIN"
+	ARRAY     = "ARRAY"
+	HASH      = "HASH"
+
+	STRUCT      = "STRUCT"
+	STRUCT_TYPE = "STRUCT_TYPE"
 )
 
 type ObjectType string
@@ -55,15 +63,30 @@ type Return struct {
 func (r *Return) Type() ObjectType { return RETURN }
 func (r *Return) Inspect() string  { return r.Value.Inspect() }
 
+// Result of a break statement, stops the loop it is in
+type Break struct{}
+
+func (b *Break) Type() ObjectType { return BREAK }
+func (b *Break) Inspect() string  { return "break" }
+
+// Result of a continue statement, goes to the next iteration of the loop it is in
+type Continue struct{}
+
+func (c *Continue) Type() ObjectType { return CONTINUE }
+func (c *Continue) Inspect() string  { return "continue" }
+
 type Error struct {
 	Message string
+	// Set by the exit builtin, the program stops with Code as its status
+	Exit bool
+	Code int
 }
 
 func (e *Error) Type() ObjectType { return ERROR }
 func (e *Error) Inspect() string  { return e.Message }
 
 type Function struct {
-	Parameters []*ast.Identifier
+	Parameters []ast.Expression
 	Body       *ast.BlockStatement
 	Env        *Environment
 }
@@ -113,6 +136,80 @@ func (m *Macro) Inspect() string {
 	return out.String()
 }
 
+// How a parameter takes its argument in a call
+type Parameter struct {
+	Source  string // As written in the function
+	Name    string // To pass it as a named argument, empty for patterns
+	Default bool   // Optional, its default value is used when it isn't passed
+	Rest    bool   // Takes the remaining positional arguments in an array
+}
+
+// Function compiled ahead of time to Go, see transpile/golang
+type CompiledFunction struct {
+	Parameters []Parameter
+	Body       string
+	// Gets an argument per parameter, nil for the ones taking their default value
+	Fn BuiltinFunction
+}
+
+func (f *CompiledFunction) Type() ObjectType { return FUNCTION }
+func (f *CompiledFunction) Inspect() string {
+	params := []string{}
+	for _, p := range f.Parameters {
+		params = append(params, p.Source)
+	}
+	return fmt.Sprintf("fn(%s) {\n%s\n}", strings.Join(params, ", "), f.Body)
+}
+
+// Macro compiled ahead of time to Go, Fn gets the text matched by each parameter
+type CompiledMacro struct {
+	Parameters []string
+	Patterns   []Object
+	Body       string
+	Fn         BuiltinFunction
+}
+
+func (m *CompiledMacro) Type() ObjectType { return MACRO }
+func (m *CompiledMacro) Inspect() string {
+	params := []string{}
+	for i, p := range m.Parameters {
+		params = append(params, p, ":", m.Patterns[i].Inspect())
+	}
+	return fmt.Sprintf("macro(%s) {\n%s\n}", strings.Join(params, ", "), m.Body)
+}
+
+type AstMacro struct {
+	Parameters []*ast.Identifier
+	Body       *ast.BlockStatement
+	Env        *Environment
+}
+
+func (m *AstMacro) Type() ObjectType { return AST_MACRO }
+func (m *AstMacro) Inspect() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range m.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString("macro")
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") {\n")
+	out.WriteString(m.Body.String())
+	out.WriteString("\n}")
+
+	return out.String()
+}
+
+type Quote struct {
+	Node ast.Node
+}
+
+func (q *Quote) Type() ObjectType { return QUOTE }
+func (q *Quote) Inspect() string  { return "QUOTE(" + q.Node.String() + ")" }
+
 type String struct {
 	Value string
 }
@@ -185,6 +282,28 @@ type HashPair struct {
 
 type Hash struct {
 	Pairs map[HashKey]HashPair
+	Keys  []HashKey // In insertion order
+}
+
+func NewHash() *Hash {
+	return &Hash{Pairs: map[HashKey]HashPair{}}
+}
+
+// A key set again keeps its position
+func (h *Hash) Set(key HashKey, pair HashPair) {
+	if _, ok := h.Pairs[key]; !ok {
+		h.Keys = append(h.Keys, key)
+	}
+	h.Pairs[key] = pair
+}
+
+// The pairs in insertion order
+func (h *Hash) Ordered() []HashPair {
+	pairs := make([]HashPair, len(h.Keys))
+	for i, key := range h.Keys {
+		pairs[i] = h.Pairs[key]
+	}
+	return pairs
 }
 
 func (h *Hash) Type() ObjectType { return HASH }
@@ -192,7 +311,7 @@ func (h *Hash) Inspect() string {
 	var out bytes.Buffer
 
 	pairs := []string{}
-	for _, e := range h.Pairs {
+	for _, e := range h.Ordered() {
 		pairs = append(pairs, e.Key.Inspect()+":"+e.Value.Inspect())
 	}
 
@@ -202,3 +321,53 @@ func (h *Hash) Inspect() string {
 
 	return out.String()
 }
+
+// Declared by a struct statement, calling it constructs a Struct
+type StructType struct {
+	Name   string
+	Fields []string
+}
+
+func (s *StructType) Type() ObjectType { return STRUCT_TYPE }
+func (s *StructType) Inspect() string {
+	if len(s.Fields) == 0 {
+		return "struct " + s.Name + " {}"
+	}
+	return "struct " + s.Name + " { " + strings.Join(s.Fields, ", ") + " }"
+}
+
+// The constructor takes every field, by position or by name
+func (s *StructType) Parameters() []Parameter {
+	params := []Parameter{}
+	for _, field := range s.Fields {
+		params = append(params, Parameter{Source: field, Name: field})
+	}
+	return params
+}
+
+type Struct struct {
+	Of     *StructType
+	Values []Object // In the order of the fields
+}
+
+func (s *Struct) Type() ObjectType { return STRUCT }
+func (s *Struct) Inspect() string {
+	fields := []string{}
+	for i, field := range s.Of.Fields {
+		value := s.Values[i].Inspect()
+		if str, ok := s.Values[i].(*String); ok {
+			value = strconv.Quote(str.Value)
+		}
+		fields = append(fields, field+": "+value)
+	}
+	return s.Of.Name + "{" + strings.Join(fields, ", ") + "}"
+}
+
+func (s *Struct) Get(field string) (Object, bool) {
+	for i, f := range s.Of.Fields {
+		if f == field {
+			return s.Values[i], true
+		}
+	}
+	return nil, false
+}
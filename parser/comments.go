@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"math"
+	"monkey/ast"
+	"sort"
+)
+
+// Gives each comment to the statement it precedes or follows in its line,
+// or to the block or program it ends
+func attachComments(program *ast.Program, comments []*ast.Comment) {
+	a := &attacher{comments: comments}
+	program.Ending = a.statements(program.Statements, math.MaxInt)
+}
+
+type attacher struct {
+	// Comments not attached yet, in order
+	comments []*ast.Comment
+}
+
+// Attaches the comments before the end line to the statements, returns the ones after the last
+func (a *attacher) statements(stmts []ast.Statement, end int) []*ast.Comment {
+	for i, stmt := range stmts {
+		start := ast.StatementLine(stmt)
+		next := end
+		if i+1 < len(stmts) {
+			next = ast.StatementLine(stmts[i+1])
+		}
+
+		commented, ok := stmt.(ast.Commented)
+		if !ok {
+			continue
+		}
+		commented.Comments().Leading = a.take(func(c *ast.Comment) bool { return c.Token.Line < start })
+
+		for _, block := range blocks(stmt) {
+			end := block.End.Line
+			if end == 0 {
+				end = next
+			}
+			block.Ending = a.statements(block.Statements, end)
+		}
+
+		commented.Comments().Trailing = a.take(func(c *ast.Comment) bool {
+			return c.Trailing && c.Token.Line >= start && c.Token.Line < next
+		})
+	}
+
+	return a.take(func(c *ast.Comment) bool { return c.Token.Line < end })
+}
+
+// Removes the comments from the start while they match
+func (a *attacher) take(match func(c *ast.Comment) bool) []*ast.Comment {
+	i := 0
+	for i < len(a.comments) && match(a.comments[i]) {
+		i++
+	}
+	taken := a.comments[:i:i]
+	a.comments = a.comments[i:]
+	return taken
+}
+
+// The blocks of the statement not inside another of its blocks, in source order
+func blocks(stmt ast.Statement) []*ast.BlockStatement {
+	found := []*ast.BlockStatement{}
+	ast.Inspect(stmt, func(node ast.Node) bool {
+		if block, ok := node.(*ast.BlockStatement); ok {
+			found = append(found, block)
+			return false
+		}
+		return true
+	})
+
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Token.Line < found[j].Token.Line })
+	return found
+}
+
+// Takes the comments between the current and the peek token inside of a literal with count
+// items so far, the ones in the line of the last item follow it and the rest wait at the end
+func (p *Parser) itemComments(c *ast.ItemComments, count int) {
+	for _, comment := range p.comments[p.between:] {
+		if comment.Trailing && count > 0 {
+			item := items(c, count-1)
+			item.Trailing = append(item.Trailing, comment)
+		} else {
+			c.Ending = append(c.Ending, comment)
+		}
+	}
+	p.comments = p.comments[:p.between]
+}
+
+// Moves the comments waiting at the end of the literal before its item i
+func leadItem(c *ast.ItemComments, i int) {
+	if len(c.Ending) > 0 {
+		items(c, i).Leading = c.Ending
+		c.Ending = nil
+	}
+}
+
+// Returns the comments of the item i, adding the ones missing up to it
+func items(c *ast.ItemComments, i int) *ast.Attached {
+	for len(c.Items) <= i {
+		c.Items = append(c.Items, ast.Attached{})
+	}
+	return &c.Items[i]
+}
@@ -0,0 +1,163 @@
+package parser
+
+import (
+	"monkey/ast"
+	"monkey/lexer"
+	"strings"
+	"testing"
+)
+
+func TestAttachComments(t *testing.T) {
+	input := `// about x
+// and more
+let x = 1 // one
+
+let f = fn(a) { // opening
+	// before return
+	return a // returned
+	// closing
+}
+x // last statement
+// the end`
+
+	p := New(lexer.NewWithComments(input))
+	program := p.ParseProgram()
+	checkParseErrors(t, p)
+
+	if len(program.Statements) != 3 {
+		t.Fatalf("expected 3 statements, got %d", len(program.Statements))
+	}
+
+	let := program.Statements[0].(*ast.LetStatement)
+	testComments(t, "x leading", let.Leading, "// about x", "// and more")
+	testComments(t, "x trailing", let.Trailing, "// one")
+
+	fn := program.Statements[1].(*ast.LetStatement)
+	testComments(t, "f leading", fn.Leading)
+	testComments(t, "f trailing", fn.Trailing)
+
+	body := fn.Value.(*ast.FunctionLiteral).Body
+	ret := body.Statements[0].(*ast.ReturnStatement)
+	testComments(t, "return leading", ret.Leading, "// opening", "// before return")
+	testComments(t, "return trailing", ret.Trailing, "// returned")
+	testComments(t, "body ending", body.Ending, "// closing")
+
+	last := program.Statements[2].(*ast.ExpressionStatement)
+	testComments(t, "x trailing", last.Trailing, "// last statement")
+	testComments(t, "program ending", program.Ending, "// the end")
+
+	if !let.Trailing[0].Trailing || let.Leading[0].Trailing {
+		t.Errorf("wrong trailing flags")
+	}
+}
+
+func TestAttachItemComments(t *testing.T) {
+	input := `let h = { // opening
+	"a": 1, // one
+	// before b
+	"b": [
+		2 // two
+		// end of b
+	],
+	// end of h
+}
+h // after h`
+
+	p := New(lexer.NewWithComments(input))
+	program := p.ParseProgram()
+	checkParseErrors(t, p)
+
+	let := program.Statements[0].(*ast.LetStatement)
+	testComments(t, "h trailing", let.Trailing)
+
+	hash := let.Value.(*ast.HashLiteral)
+	testComments(t, "a leading", hash.Item(0).Leading, "// opening")
+	testComments(t, "a trailing", hash.Item(0).Trailing, "// one")
+	testComments(t, "b leading", hash.Item(1).Leading, "// before b")
+	testComments(t, "h ending", hash.Ending, "// end of h")
+
+	array := hash.Pairs[1].Value.(*ast.ArrayLiteral)
+	testComments(t, "2 trailing", array.Item(0).Trailing, "// two")
+	testComments(t, "b ending", array.Ending, "// end of b")
+
+	last := program.Statements[1].(*ast.ExpressionStatement)
+	testComments(t, "h leading", last.Leading)
+	testComments(t, "h trailing", last.Trailing, "// after h")
+}
+
+func TestCommentsNotKeptByDefault(t *testing.T) {
+	p := New(lexer.New("// about x\nlet x = 1 // one"))
+	program := p.ParseProgram()
+	checkParseErrors(t, p)
+
+	let := program.Statements[0].(*ast.LetStatement)
+	if len(let.Leading) != 0 || len(let.Trailing) != 0 {
+		t.Errorf("expected no comments, got %v %v", let.Leading, let.Trailing)
+	}
+}
+
+func testComments(t *testing.T, name string, comments []*ast.Comment, expected ...string) {
+	t.Helper()
+
+	texts := []string{}
+	for _, c := range comments {
+		texts = append(texts, c.Text())
+	}
+
+	if strings.Join(texts, "|") != strings.Join(expected, "|") {
+		t.Errorf("wrong %s comments. expected=%q, got=%q", name, expected, texts)
+	}
+}
+
+func TestDocComments(t *testing.T) {
+	input := `/// Adds two numbers
+///
+/// Works with strings too
+let add = fn(a, b) { a + b }
+
+/// Not right before a let
+
+let x = 1
+/// Belongs to no let
+x
+let f = fn() {
+	/// Inside a block
+	let y = 2
+}
+/// A point in the plane
+struct Point { x, y }`
+
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParseErrors(t, p)
+
+	tests := []struct {
+		let *ast.LetStatement
+		doc string
+	}{
+		{program.Statements[0].(*ast.LetStatement), "Adds two numbers\n\nWorks with strings too"},
+		{program.Statements[1].(*ast.LetStatement), ""},
+		{program.Statements[3].(*ast.LetStatement), ""},
+		{program.Statements[3].(*ast.LetStatement).Value.(*ast.FunctionLiteral).Body.Statements[0].(*ast.LetStatement), "Inside a block"},
+	}
+
+	for _, tt := range tests {
+		if tt.let.Doc != tt.doc {
+			t.Errorf("wrong doc for %s. expected=%q, got=%q", tt.let.Name, tt.doc, tt.let.Doc)
+		}
+	}
+
+	if doc := program.Statements[4].(*ast.StructStatement).Doc; doc != "A point in the plane" {
+		t.Errorf("wrong doc for Point. got=%q", doc)
+	}
+}
+
+func TestUnterminatedBlockComment(t *testing.T) {
+	p := New(lexer.New("let x = 1 /* not closed"))
+	p.ParseProgram()
+
+	expected := []string{"Error at line 1. block comment not terminated"}
+	if strings.Join(p.Errors(), "|") != strings.Join(expected, "|") {
+		t.Errorf("wrong errors. expected=%q, got=%q", expected, p.Errors())
+	}
+}
@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"monkey/ast"
+	"monkey/token"
+)
+
+func (p *Parser) parseWhileExpression() ast.Expression {
+	exp := &ast.WhileExpression{Token: p.currToken}
+
+	p.nextToken()
+	exp.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	exp.Body = p.parseLoopBody()
+	return exp
+}
+
+func (p *Parser) parseForExpression() ast.Expression {
+	exp := &ast.ForExpression{Token: p.currToken}
+
+	exp.Name = p.parseBinding()
+	if exp.Name == nil || !p.expectPeek(token.IN) {
+		return nil
+	}
+
+	p.nextToken()
+	exp.Iterable = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	exp.Body = p.parseLoopBody()
+	return exp
+}
+
+func (p *Parser) parseLoopBody() *ast.BlockStatement {
+	p.loops++
+	body := p.ParseBlockStatement()
+	p.loops--
+	return body
+}
+
+// Parses break and continue, which must be in the body of a loop and not of a function inside of it
+func (p *Parser) parseLoopControl() ast.Statement {
+	if p.loops == 0 {
+		p.lineError(p.currToken.Line, "%s outside of a loop", p.currToken.Literal)
+	}
+
+	var stmt ast.Statement = &ast.ContinueStatement{Token: p.currToken}
+	if p.currTokenIs(token.BREAK) {
+		stmt = &ast.BreakStatement{Token: p.currToken}
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"monkey/ast"
+	"monkey/lexer"
+	"strings"
+	"testing"
+)
+
+func TestWhileExpression(t *testing.T) {
+	stmt := parseSingleStatement(t, "while x < 10 { let x = x + 1; if x == 5 { continue } }")
+
+	exp, ok := stmt.Expression.(*ast.WhileExpression)
+	if !ok {
+		t.Fatalf("expected WhileExpression, got %T", stmt.Expression)
+	}
+
+	if !testInfixExpression(t, exp.Condition, "x", "<", 10) {
+		return
+	}
+	if len(exp.Body.Statements) != 2 {
+		t.Fatalf("expected 2 statements in the body, got %d", len(exp.Body.Statements))
+	}
+	if exp.String() != "while (x < 10) let x = (x + 1);if (x == 5) continue" {
+		t.Errorf("wrong while. got=%q", exp.String())
+	}
+}
+
+func TestForExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		name     string
+		iterable string
+		body     string
+	}{
+		{"for x in xs { echo(x) }", "x", "xs", "echo(x)"},
+		{"for [k, v] in pairs(h) { break; }", "[k, v]", "pairs(h)", "break"},
+		{"for c in \"abc\" {}", "c", "abc", ""},
+	}
+
+	for _, tt := range tests {
+		stmt := parseSingleStatement(t, tt.input)
+
+		exp, ok := stmt.Expression.(*ast.ForExpression)
+		if !ok {
+			t.Fatalf("expected ForExpression, got %T", stmt.Expression)
+		}
+
+		if exp.Name.String() != tt.name {
+			t.Errorf("wrong name. expected=%q, got=%q", tt.name, exp.Name.String())
+		}
+		if exp.Iterable.String() != tt.iterable {
+			t.Errorf("wrong iterable. expected=%q, got=%q", tt.iterable, exp.Iterable.String())
+		}
+		if exp.Body.String() != tt.body {
+			t.Errorf("wrong body. expected=%q, got=%q", tt.body, exp.Body.String())
+		}
+	}
+}
+
+func TestLoopErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"break", "Error at line 1. break outside of a loop"},
+		{"if x { continue }", "Error at line 1. continue outside of a loop"},
+		{"while x {\n\tlet f = fn() { break }\n}", "Error at line 2. break outside of a loop"},
+		{"for x xs {}", "expected next token to be IN, got IDENT instead"},
+		{"for 1 in xs {}", "expected next token to be IDENT, got INT instead"},
+		{"for [a, a] in xs {}", "Error at line 1. a is bound more than once in the pattern"},
+		{"while x", "expected next token to be {, got EOF instead"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 || !strings.HasSuffix(p.Errors()[0], tt.expected) {
+			t.Errorf("wrong errors for %q. expected=%q, got=%q", tt.input, tt.expected, p.Errors())
+		}
+	}
+
+	p := New(lexer.New("for x in xs { while true { break }; fn() { for y in x { continue } }; continue }"))
+	p.ParseProgram()
+	checkParseErrors(t, p)
+}
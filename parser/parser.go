@@ -15,8 +15,11 @@ const (
 	LOWEST
 	OR
 	AND
+	BIT_OR
+	BIT_AND
 	EQUALS
 	LESSGREATER // < or >
+	PIPE
 	SUM
 	PRODUCT
 	PREFIX
@@ -27,12 +30,15 @@ const (
 var precedences = map[token.TokenType]int{
 	token.OR:       OR,
 	token.AND:      AND,
+	token.BIT_OR:   BIT_OR,
+	token.BIT_AND:  BIT_AND,
 	token.EQ:       EQUALS,
 	token.NE:       EQUALS,
 	token.LT:       LESSGREATER,
 	token.GT:       LESSGREATER,
 	token.LE:       LESSGREATER,
 	token.GE:       LESSGREATER,
+	token.PIPE:     PIPE,
 	token.PLUS:     SUM,
 	token.MINUS:    SUM,
 	token.SLASH:    PRODUCT,
@@ -40,6 +46,7 @@ var precedences = map[token.TokenType]int{
 	token.PERCENT:  PRODUCT,
 	token.LPAREN:   CALL,
 	token.LBRACKET: INDEX,
+	token.DOT:      INDEX,
 }
 
 type (
@@ -52,6 +59,14 @@ type Parser struct {
 	currToken token.Token
 	peekToken token.Token
 	errors    []string
+	// Read when the lexer returns comments, attached once the program is parsed
+	comments []*ast.Comment
+	// Where the comments between the current and the peek token start in comments
+	between int
+	// Doc comments read since the current statement started
+	docs []token.Token
+	// Loops around the current statement, up to the function it is in
+	loops int
 
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
@@ -78,6 +93,10 @@ func New(lexer *lexer.Lexer) *Parser {
 	p.prefixParseFns[token.TEMPLATE] = p.parseTemplate
 	p.prefixParseFns[token.LBRACKET] = p.parseArrayLiteral
 	p.prefixParseFns[token.LBRACE] = p.parseHashLiteral
+	p.prefixParseFns[token.MATCH] = p.parseMatchExpression
+	p.prefixParseFns[token.WHILE] = p.parseWhileExpression
+	p.prefixParseFns[token.FOR] = p.parseForExpression
+	p.prefixParseFns[token.ELLIPSIS] = p.parseRestElement
 
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
 	p.infixParseFns[token.PLUS] = p.parseInfixExpression
@@ -87,6 +106,9 @@ func New(lexer *lexer.Lexer) *Parser {
 	p.infixParseFns[token.SLASH] = p.parseInfixExpression
 	p.infixParseFns[token.AND] = p.parseInfixExpression
 	p.infixParseFns[token.OR] = p.parseInfixExpression
+	p.infixParseFns[token.BIT_AND] = p.parseInfixExpression
+	p.infixParseFns[token.BIT_OR] = p.parseInfixExpression
+	p.infixParseFns[token.PIPE] = p.parsePipeExpression
 	p.infixParseFns[token.EQ] = p.parseInfixExpression
 	p.infixParseFns[token.NE] = p.parseInfixExpression
 	p.infixParseFns[token.LT] = p.parseInfixExpression
@@ -95,6 +117,7 @@ func New(lexer *lexer.Lexer) *Parser {
 	p.infixParseFns[token.GE] = p.parseInfixExpression
 	p.infixParseFns[token.LPAREN] = p.parseCallExpression
 	p.infixParseFns[token.LBRACKET] = p.parseIndexExpression
+	p.infixParseFns[token.DOT] = p.parseMemberExpression
 	return p
 }
 
@@ -115,6 +138,16 @@ func (p *Parser) currPrecedence() int {
 func (p *Parser) nextToken() {
 	p.currToken = p.peekToken
 	p.peekToken = p.l.NextToken()
+
+	p.between = len(p.comments)
+	for p.peekToken.Type == token.COMMENT || p.peekToken.Type == token.DOC {
+		if p.peekToken.Type == token.DOC {
+			p.docs = append(p.docs, p.peekToken)
+		}
+		trailing := p.peekToken.Line == p.currToken.Line+strings.Count(p.currToken.Literal, "\n")
+		p.comments = append(p.comments, &ast.Comment{Token: p.peekToken, Trailing: trailing})
+		p.peekToken = p.l.NextToken()
+	}
 }
 
 func (p *Parser) ParseProgram() *ast.Program {
@@ -129,6 +162,12 @@ func (p *Parser) ParseProgram() *ast.Program {
 		p.nextToken()
 	}
 
+	p.errors = append(p.errors, p.l.Errors()...)
+
+	if len(p.comments) > 0 && len(p.errors) == 0 {
+		attachComments(program, p.comments)
+	}
+
 	return program
 }
 
@@ -141,12 +180,31 @@ func (p *Parser) peekError(t token.TokenType) {
 	p.errors = append(p.errors, msg)
 }
 
+func (p *Parser) lineError(line int, format string, a ...any) {
+	p.errors = append(p.errors, fmt.Sprintf("Error at line %d. ", line)+fmt.Sprintf(format, a...))
+}
+
 func (p *Parser) parseStatement() ast.Statement {
+	docs := p.docs
+	p.docs = nil
+
 	switch p.currToken.Type {
 	case token.LET:
-		return p.parseLetStatement()
+		stmt := p.parseLetStatement()
+		if stmt != nil {
+			stmt.Doc = documentation(docs, stmt.Token.Line)
+		}
+		return stmt
+	case token.STRUCT:
+		stmt := p.parseStructStatement()
+		if stmt != nil {
+			stmt.Doc = documentation(docs, stmt.Token.Line)
+		}
+		return stmt
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.BREAK, token.CONTINUE:
+		return p.parseLoopControl()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -164,40 +222,58 @@ func (p *Parser) parseGroupedExpression() ast.Expression {
 func (p *Parser) parseIfElseExpression() ast.Expression {
 	exp := &ast.IfExpression{Token: p.currToken}
 
-	if p.peekTokenIs(token.LPAREN) {
-		p.nextToken()
-	}
+	for {
+		branch, ok := p.parseIfBranch()
+		if !ok {
+			return nil
+		}
+		exp.Branches = append(exp.Branches, branch)
 
-	p.nextToken()
-	exp.Condition = p.parseExpression(LOWEST)
+		if !p.peekTokenIs(token.ELSE) {
+			return exp
+		}
+		p.nextToken()
 
-	if p.peekTokenIs(token.RPAREN) {
+		if !p.peekTokenIs(token.IF) {
+			break
+		}
 		p.nextToken()
 	}
 
 	if !p.expectPeek(token.LBRACE) {
 		return nil
 	}
+	exp.Alternative = p.ParseBlockStatement()
 
-	exp.Consequence = p.ParseBlockStatement()
+	return exp
+}
 
-	if p.peekTokenIs(token.ELSE) {
+// Parses the condition, with optional parentheses, and the block after an if
+func (p *Parser) parseIfBranch() (ast.IfBranch, bool) {
+	if p.peekTokenIs(token.LPAREN) {
 		p.nextToken()
+	}
 
-		if !p.expectPeek(token.LBRACE) {
-			return nil
-		}
+	p.nextToken()
+	condition := p.parseExpression(LOWEST)
 
-		exp.Alternative = p.ParseBlockStatement()
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
 	}
 
-	return exp
+	if !p.expectPeek(token.LBRACE) {
+		return ast.IfBranch{}, false
+	}
+
+	return ast.IfBranch{Condition: condition, Consequence: p.ParseBlockStatement()}, true
 }
 
 func (p *Parser) parseHashLiteral() ast.Expression {
-	exp := &ast.HashLiteral{Token: p.currToken, Pairs: map[ast.Expression]ast.Expression{}}
+	exp := &ast.HashLiteral{Token: p.currToken}
 
 	for !p.peekTokenIs(token.RBRACE) {
+		p.itemComments(&exp.ItemComments, len(exp.Pairs))
+		leadItem(&exp.ItemComments, len(exp.Pairs))
 		p.nextToken()
 
 		key := p.parseExpression(LOWEST)
@@ -207,12 +283,14 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 		p.nextToken()
 
 		value := p.parseExpression(LOWEST)
-		exp.Pairs[key] = value
+		exp.Pairs = append(exp.Pairs, ast.HashPair{Key: key, Value: value})
+		p.itemComments(&exp.ItemComments, len(exp.Pairs))
 
 		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
 			return nil
 		}
 	}
+	p.itemComments(&exp.ItemComments, len(exp.Pairs))
 
 	if !p.expectPeek(token.RBRACE) {
 		return nil
@@ -223,22 +301,28 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 
 func (p *Parser) parseArrayLiteral() ast.Expression {
 	exp := &ast.ArrayLiteral{ExpressionsContainer: ast.ExpressionsContainer{Token: p.currToken}}
+	p.itemComments(&exp.ItemComments, 0)
 	p.nextToken()
 
 	if !p.currTokenIs(token.RBRACKET) {
+		leadItem(&exp.ItemComments, 0)
 		param := p.parseExpression(LOWEST)
 		exp.Elements = append(exp.Elements, param)
+		p.itemComments(&exp.ItemComments, len(exp.Elements))
 
 		for p.peekTokenIs(token.COMMA) {
 			p.nextToken()
+			p.itemComments(&exp.ItemComments, len(exp.Elements))
 
 			if p.peekTokenIs(token.RBRACKET) {
 				break
 			}
 
+			leadItem(&exp.ItemComments, len(exp.Elements))
 			p.nextToken()
 			param := p.parseExpression(LOWEST)
 			exp.Elements = append(exp.Elements, param)
+			p.itemComments(&exp.ItemComments, len(exp.Elements))
 		}
 
 		if !p.expectPeek(token.RBRACKET) {
@@ -256,24 +340,96 @@ func (p *Parser) parseFunctionExpression() ast.Expression {
 		return nil
 	}
 
-	p.nextToken()
+	params, ok := p.parseFunctionParameters()
+	if !ok {
+		return nil
+	}
+	exp.Parameters = params
 
-	if !p.currTokenIs(token.RPAREN) {
-		param := p.parseIdentifier().(*ast.Identifier)
-		exp.Parameters = append(exp.Parameters, param)
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
 
-		for p.peekTokenIs(token.COMMA) {
+	loops := p.loops
+	p.loops = 0
+	exp.Body = p.ParseBlockStatement()
+	p.loops = loops
+
+	return exp
+}
+
+// Parses names or array and hash patterns, with an optional default value,
+// and a last ...rest parameter until the closing parenthesis
+func (p *Parser) parseFunctionParameters() ([]ast.Expression, bool) {
+	params := []ast.Expression{}
+
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		return params, true
+	}
+
+	for {
+		var param ast.Expression
+		if p.peekTokenIs(token.ELLIPSIS) {
+			p.nextToken()
+			param = p.parseRestElement()
+		} else {
+			param = p.parseBinding()
+		}
+		if param == nil {
+			return nil, false
+		}
+
+		if _, ok := param.(*ast.RestElement); !ok && p.peekTokenIs(token.ASSIGN) {
 			p.nextToken()
+			def := &ast.DefaultParameter{Token: p.currToken, Parameter: param}
 			p.nextToken()
-			param := p.parseIdentifier().(*ast.Identifier)
-			exp.Parameters = append(exp.Parameters, param)
+			def.Value = p.parseExpression(LOWEST)
+			param = def
 		}
+		params = append(params, param)
 
-		if !p.expectPeek(token.RPAREN) {
-			return nil
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		if rest, ok := param.(*ast.RestElement); ok {
+			p.lineError(rest.Token.Line, "%s must be the last parameter", rest.String())
+			return nil, false
 		}
+		p.nextToken()
 	}
 
+	return params, p.expectPeek(token.RPAREN)
+}
+
+// Parses the identifiers from the current token until the closing parenthesis
+func (p *Parser) parseParameters() ([]*ast.Identifier, bool) {
+	params := []*ast.Identifier{}
+
+	if p.currTokenIs(token.RPAREN) {
+		return params, true
+	}
+
+	params = append(params, p.parseIdentifier().(*ast.Identifier))
+
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		p.nextToken()
+		params = append(params, p.parseIdentifier().(*ast.Identifier))
+	}
+
+	return params, p.expectPeek(token.RPAREN)
+}
+
+func (p *Parser) parseAstMacroExpression(tok token.Token) ast.Expression {
+	exp := &ast.AstMacroLiteral{Token: tok}
+
+	params, ok := p.parseParameters()
+	if !ok {
+		return nil
+	}
+	exp.Parameters = params
+
 	if !p.expectPeek(token.LBRACE) {
 		return nil
 	}
@@ -291,6 +447,11 @@ func (p *Parser) parseMacroExpression() ast.Expression {
 
 	p.nextToken()
 
+	// Plain parameters or a block body make a macro operating on the AST
+	if p.currTokenIs(token.IDENT) && !p.peekTokenIs(token.COLON) {
+		return p.parseAstMacroExpression(exp.Token)
+	}
+
 	if !p.currTokenIs(token.RPAREN) {
 		parseParam := func() bool {
 			param := p.parseIdentifier().(*ast.Identifier)
@@ -329,6 +490,10 @@ func (p *Parser) parseMacroExpression() ast.Expression {
 		return nil
 	}
 
+	if len(exp.Parameters) == 0 && !p.peekTokenIs(token.TEMPLATE) {
+		return &ast.AstMacroLiteral{Token: exp.Token, Parameters: []*ast.Identifier{}, Body: p.ParseBlockStatement()}
+	}
+
 	p.nextToken()
 
 	tmpl, ok := p.parseTemplate().(*ast.TemplateString)
@@ -355,6 +520,7 @@ func (p *Parser) ParseBlockStatement() *ast.BlockStatement {
 		}
 		p.nextToken()
 	}
+	block.End = p.currToken
 
 	return block
 }
@@ -449,6 +615,17 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	return pe
 }
 
+func (p *Parser) parseMemberExpression(left ast.Expression) ast.Expression {
+	me := &ast.MemberExpression{Token: p.currToken, Left: left}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	me.Name = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+
+	return me
+}
+
 func (p *Parser) parseCallExpression(left ast.Expression) ast.Expression {
 	// defer untrace(trace("parseCallExpression"))
 	ce := &ast.CallExpression{Token: p.currToken, Function: left}
@@ -458,20 +635,73 @@ func (p *Parser) parseCallExpression(left ast.Expression) ast.Expression {
 		return ce
 	}
 
-	ce.Arguments = append(ce.Arguments, p.parseExpression(LOWEST))
+	ce.Arguments = append(ce.Arguments, p.parseArgument())
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
+
+		if p.peekTokenIs(token.RPAREN) {
+			break
+		}
+
 		p.nextToken()
-		ce.Arguments = append(ce.Arguments, p.parseExpression(LOWEST))
+		ce.Arguments = append(ce.Arguments, p.parseArgument())
 	}
 
 	if !p.expectPeek(token.RPAREN) {
 		return nil
 	}
 
+	named := map[string]bool{}
+	for _, arg := range ce.Arguments {
+		arg, ok := arg.(*ast.NamedArgument)
+		switch {
+		case !ok && len(named) > 0:
+			p.lineError(ce.Token.Line, "positional arguments must go before the named ones in %s", ce.String())
+			return nil
+		case ok && named[arg.Name.Value]:
+			p.lineError(ce.Token.Line, "%s is passed more than once in %s", arg.Name.Value, ce.String())
+			return nil
+		case ok:
+			named[arg.Name.Value] = true
+		}
+	}
+
 	return ce
 }
 
+// Parses value |> f(args) as the call f(value, args)
+func (p *Parser) parsePipeExpression(left ast.Expression) ast.Expression {
+	line := p.currToken.Line
+	p.nextToken()
+
+	right := p.parseExpression(PIPE)
+	call, ok := right.(*ast.CallExpression)
+	if !ok {
+		if right != nil {
+			p.lineError(line, "%s is not a call, |> passes the value as the first argument of a call", right.String())
+		}
+		return nil
+	}
+
+	piped := *call
+	piped.Arguments = append([]ast.Expression{left}, call.Arguments...)
+	piped.Piped = true
+	return &piped
+}
+
+// Parses an argument, named when it starts with name:
+func (p *Parser) parseArgument() ast.Expression {
+	if !p.currTokenIs(token.IDENT) || !p.peekTokenIs(token.COLON) {
+		return p.parseExpression(LOWEST)
+	}
+
+	arg := &ast.NamedArgument{Token: p.currToken, Name: &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}}
+	p.nextToken()
+	p.nextToken()
+	arg.Value = p.parseExpression(LOWEST)
+	return arg
+}
+
 func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	// defer untrace(trace("parseExpressionStatement"))
 	stmt := &ast.ExpressionStatement{Token: p.currToken}
@@ -527,12 +757,11 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 func (p *Parser) parseLetStatement() *ast.LetStatement {
 	stmt := &ast.LetStatement{Token: p.currToken}
 
-	if !p.expectPeek(token.IDENT) {
+	stmt.Name = p.parseBinding()
+	if stmt.Name == nil {
 		return nil
 	}
 
-	stmt.Name = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
-
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
 	}
@@ -548,6 +777,53 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 	return stmt
 }
 
+func (p *Parser) parseStructStatement() *ast.StructStatement {
+	stmt := &ast.StructStatement{Token: p.currToken}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	fields := map[string]bool{}
+	for !p.peekTokenIs(token.RBRACE) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		field := &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+		if fields[field.Value] {
+			p.lineError(field.Token.Line, "%s is a field of %s more than once", field.Value, stmt.Name.Value)
+		}
+		fields[field.Value] = true
+		stmt.Fields = append(stmt.Fields, field)
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	p.nextToken()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// Text of the doc comments in the lines right before the line
+func documentation(docs []token.Token, line int) string {
+	lines := []string{}
+	for i := len(docs) - 1; i >= 0 && docs[i].Line == line-len(lines)-1; i-- {
+		text := strings.TrimPrefix(docs[i].Literal, "///")
+		lines = append([]string{strings.TrimPrefix(text, " ")}, lines...)
+	}
+	return strings.Join(lines, "\n")
+}
+
 // Only advances to the next token if is the expected one
 func (p *Parser) expectPeek(t token.TokenType) bool {
 	if p.peekTokenIs(t) {
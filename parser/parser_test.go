@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"monkey/ast"
 	"monkey/lexer"
+	"strings"
 	"testing"
 )
 
@@ -55,7 +56,8 @@ func TestParsingHashLiteralsWithExpressions(t *testing.T) {
 		},
 	}
 
-	for key, value := range hash.Pairs {
+	for _, pair := range hash.Pairs {
+		key, value := pair.Key, pair.Value
 		literal, ok := key.(*ast.StringLiteral)
 		if !ok {
 			t.Errorf("key is not ast.StringLiteral. got=%T", key)
@@ -71,6 +73,27 @@ func TestParsingHashLiteralsWithExpressions(t *testing.T) {
 	}
 }
 
+func TestParsingHashLiteralOrder(t *testing.T) {
+	stmt := parseSingleStatement(t, `{ "b": 1, 3: 2, "a": 3, true: 4 }`)
+
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+
+	expected := []string{"b", "3", "a", "true"}
+	if len(hash.Pairs) != len(expected) {
+		t.Fatalf("len(hash.Pairs) not %d. got=%d", len(expected), len(hash.Pairs))
+	}
+
+	for i, pair := range hash.Pairs {
+		if pair.Key.String() != expected[i] {
+			t.Errorf("wrong key at %d. expected=%q, got=%q", i, expected[i], pair.Key.String())
+		}
+		testIntegerLiteral(t, pair.Value, int64(i+1))
+	}
+}
+
 func TestParsingEmptyHashLiteral(t *testing.T) {
 	stmt := parseSingleStatement(t, "{}")
 
@@ -101,7 +124,8 @@ func TestParsingHashIntegerLiterals(t *testing.T) {
 		2: 4,
 	}
 
-	for key, value := range hash.Pairs {
+	for _, pair := range hash.Pairs {
+		key, value := pair.Key, pair.Value
 		literal, ok := key.(*ast.IntegerLiteral)
 		if !ok {
 			t.Errorf("key is not a IntegerLiteral got %T", key)
@@ -127,7 +151,8 @@ func TestParsingHashBooleanLiterals(t *testing.T) {
 		false: 4,
 	}
 
-	for key, value := range hash.Pairs {
+	for _, pair := range hash.Pairs {
+		key, value := pair.Key, pair.Value
 		literal, ok := key.(*ast.BooleanLiteral)
 		if !ok {
 			t.Errorf("key is not a BooleanLiteral got %T", key)
@@ -154,7 +179,8 @@ func TestParsingHashStringLiterals(t *testing.T) {
 		"it":   7,
 	}
 
-	for key, value := range hash.Pairs {
+	for _, pair := range hash.Pairs {
+		key, value := pair.Key, pair.Value
 		literal, ok := key.(*ast.StringLiteral)
 		if !ok {
 			t.Errorf("key is not a StringLiteral got %T", key)
@@ -274,15 +300,19 @@ func TestIfExpression(t *testing.T) {
 		t.Errorf("expected TokenLiteral to be if, got %s", exp.TokenLiteral())
 	}
 
-	if !testInfixExpression(t, exp.Condition, "x", "<", "y") {
+	if len(exp.Branches) != 1 {
+		t.Fatalf("expected 1 branch, got %d", len(exp.Branches))
+	}
+
+	if !testInfixExpression(t, exp.Branches[0].Condition, "x", "<", "y") {
 		return
 	}
 
-	if len(exp.Consequence.Statements) != 1 {
-		t.Errorf("expected Consequence.Statements to be 1, got %d", len(exp.Consequence.Statements))
+	if len(exp.Branches[0].Consequence.Statements) != 1 {
+		t.Errorf("expected Consequence.Statements to be 1, got %d", len(exp.Branches[0].Consequence.Statements))
 	}
 
-	blockStmt, ok := exp.Consequence.Statements[0].(*ast.ExpressionStatement)
+	blockStmt, ok := exp.Branches[0].Consequence.Statements[0].(*ast.ExpressionStatement)
 	if !ok {
 		t.Errorf("expected ExpressionStatement got %T", blockStmt)
 		return
@@ -307,15 +337,19 @@ func TestIfElseExpression(t *testing.T) {
 		return
 	}
 
-	if !testInfixExpression(t, exp.Condition, "x", "<", "y") {
+	if len(exp.Branches) != 1 {
+		t.Fatalf("expected 1 branch, got %d", len(exp.Branches))
+	}
+
+	if !testInfixExpression(t, exp.Branches[0].Condition, "x", "<", "y") {
 		return
 	}
 
-	if len(exp.Consequence.Statements) != 1 {
-		t.Errorf("expected Consequence.Statements to be 1, got %d", len(exp.Consequence.Statements))
+	if len(exp.Branches[0].Consequence.Statements) != 1 {
+		t.Errorf("expected Consequence.Statements to be 1, got %d", len(exp.Branches[0].Consequence.Statements))
 	}
 
-	blockIfStmt, ok := exp.Consequence.Statements[0].(*ast.ExpressionStatement)
+	blockIfStmt, ok := exp.Branches[0].Consequence.Statements[0].(*ast.ExpressionStatement)
 	if !ok {
 		t.Errorf("expected ExpressionStatement got %T", blockIfStmt)
 		return
@@ -340,6 +374,37 @@ func TestIfElseExpression(t *testing.T) {
 	}
 }
 
+func TestElseIfExpression(t *testing.T) {
+	stmt := parseSingleStatement(t, "if x < y { x } else if (x > y) { y } else if z { z } else { 0 }")
+
+	exp, ok := stmt.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("expected IfExpression, got %T", stmt.Expression)
+	}
+
+	expected := []string{"(x < y)", "(x > y)", "z"}
+	if len(exp.Branches) != len(expected) {
+		t.Fatalf("expected %d branches, got %d", len(expected), len(exp.Branches))
+	}
+
+	for i, branch := range exp.Branches {
+		if branch.Condition.String() != expected[i] {
+			t.Errorf("wrong condition %d. expected=%q, got=%q", i, expected[i], branch.Condition.String())
+		}
+		if len(branch.Consequence.Statements) != 1 {
+			t.Errorf("expected 1 statement in branch %d, got %d", i, len(branch.Consequence.Statements))
+		}
+	}
+
+	if exp.Alternative == nil || exp.Alternative.String() != "0" {
+		t.Errorf("wrong alternative. got=%v", exp.Alternative)
+	}
+
+	if exp.String() != "if (x < y) x else if (x > y) y else if z z else 0" {
+		t.Errorf("wrong string. got=%q", exp.String())
+	}
+}
+
 func TestFunctionLiteral(t *testing.T) {
 	stmt := parseSingleStatement(t, "fn(x, y) { x + y }")
 
@@ -422,6 +487,43 @@ func TestParsingMacro(t *testing.T) {
 	testLiteralExpression(t, exp.Body.Elements[0], `"x + y"`)
 }
 
+func TestParsingAstMacro(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedParams []string
+		expectedBody   string
+	}{
+		{"macro(x, y) { x + y; }", []string{"x", "y"}, "(x + y)"},
+		{"macro(x) { quote(unquote(x) * 2) }", []string{"x"}, "quote((unquote(x) * 2))"},
+		{"macro() { quote(1) }", []string{}, "quote(1)"},
+	}
+
+	for _, tt := range tests {
+		stmt := parseSingleStatement(t, tt.input)
+
+		exp, ok := stmt.Expression.(*ast.AstMacroLiteral)
+		if !ok {
+			t.Fatalf("expected AstMacroLiteral, got %T", stmt.Expression)
+		}
+
+		if exp.TokenLiteral() != "macro" {
+			t.Errorf("expected TokenLiteral to be macro, got %s", exp.TokenLiteral())
+		}
+
+		if len(exp.Parameters) != len(tt.expectedParams) {
+			t.Fatalf("expected length of parameters to be %d, got %d", len(tt.expectedParams), len(exp.Parameters))
+		}
+
+		for i, p := range tt.expectedParams {
+			testLiteralExpression(t, exp.Parameters[i], p)
+		}
+
+		if exp.Body.String() != tt.expectedBody {
+			t.Errorf("expected body to be %s, got %s", tt.expectedBody, exp.Body.String())
+		}
+	}
+}
+
 func TestFunctionParameters(t *testing.T) {
 	tests := []struct {
 		input          string
@@ -449,27 +551,123 @@ func TestFunctionParameters(t *testing.T) {
 	}
 }
 
-func TestCallExpression(t *testing.T) {
-	stmt := parseSingleStatement(t, "add(1, 2 * 3, 4 + 5)")
+func TestDefaultAndRestParameters(t *testing.T) {
+	stmt := parseSingleStatement(t, "fn(a, b = a + 1, [c] = [], ...rest) { a }")
 
-	exp, ok := stmt.Expression.(*ast.CallExpression)
+	fn, ok := stmt.Expression.(*ast.FunctionLiteral)
 	if !ok {
-		t.Errorf("expected CallExpression, got %T", exp)
-		return
+		t.Fatalf("expected FunctionLiteral, got %T", stmt.Expression)
 	}
 
-	if !testIdentifier(t, exp.Function, "add") {
-		return
+	expected := []string{"a", "b = (a + 1)", "[c] = []", "...rest"}
+	if len(fn.Parameters) != len(expected) {
+		t.Fatalf("expected %d parameters, got %d", len(expected), len(fn.Parameters))
+	}
+	for i, p := range fn.Parameters {
+		if p.String() != expected[i] {
+			t.Errorf("wrong parameter %d. expected=%q, got=%q", i, expected[i], p.String())
+		}
+	}
+
+	if _, ok := fn.Parameters[1].(*ast.DefaultParameter); !ok {
+		t.Errorf("expected DefaultParameter, got %T", fn.Parameters[1])
+	}
+	if _, ok := fn.Parameters[3].(*ast.RestElement); !ok {
+		t.Errorf("expected RestElement, got %T", fn.Parameters[3])
+	}
+}
+
+func TestNamedArguments(t *testing.T) {
+	stmt := parseSingleStatement(t, "f(1, y: 2 + 3, z: {})")
+
+	call, ok := stmt.Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("expected CallExpression, got %T", stmt.Expression)
 	}
 
-	if len(exp.Arguments) != 3 {
-		t.Errorf("expected length of parameters to be 3, got %d", len(exp.Arguments))
+	if call.String() != "f(1, y: (2 + 3), z: {})" {
+		t.Errorf("wrong call. got=%q", call.String())
+	}
+	named, ok := call.Arguments[1].(*ast.NamedArgument)
+	if !ok || named.Name.Value != "y" {
+		t.Errorf("expected NamedArgument y, got %T (%s)", call.Arguments[1], call.Arguments[1])
+	}
+}
+
+func TestPipeExpression(t *testing.T) {
+	stmt := parseSingleStatement(t, "xs |> map(f, 1)")
+
+	call, ok := stmt.Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("expected CallExpression, got %T", stmt.Expression)
+	}
+
+	if !call.Piped || call.Function.String() != "map" {
+		t.Errorf("expected a piped call to map, got %s", call)
+	}
+	expected := []string{"xs", "f", "1"}
+	if len(call.Arguments) != len(expected) {
+		t.Fatalf("expected %d arguments, got %d", len(expected), len(call.Arguments))
+	}
+	for i, arg := range call.Arguments {
+		if arg.String() != expected[i] {
+			t.Errorf("wrong argument %d. expected=%q, got=%q", i, expected[i], arg.String())
+		}
+	}
+
+	p := New(lexer.New("x |> f"))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 || p.Errors()[0] != "Error at line 1. f is not a call, |> passes the value as the first argument of a call" {
+		t.Errorf("expected an error for a pipe without a call, got %q", p.Errors())
+	}
+}
+
+func TestMemberExpression(t *testing.T) {
+	stmt := parseSingleStatement(t, `"abc".len()`)
+
+	call, ok := stmt.Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("expected CallExpression, got %T", stmt.Expression)
+	}
+
+	member, ok := call.Function.(*ast.MemberExpression)
+	if !ok {
+		t.Fatalf("expected MemberExpression, got %T", call.Function)
+	}
+	if !testString(t, member.Left, `"abc"`) || !testIdentifier(t, member.Name, "len") {
 		return
 	}
 
-	testLiteralExpression(t, exp.Arguments[0], 1)
-	testInfixExpression(t, exp.Arguments[1], 2, "*", 3)
-	testInfixExpression(t, exp.Arguments[2], 4, "+", 5)
+	p := New(lexer.New("x.1"))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 || !strings.HasSuffix(p.Errors()[0], "expected next token to be IDENT, got INT instead") {
+		t.Errorf("expected an error for a member without a name, got %q", p.Errors())
+	}
+}
+
+func TestCallExpression(t *testing.T) {
+	for _, input := range []string{"add(1, 2 * 3, 4 + 5)", "add(\n\t1,\n\t2 * 3,\n\t4 + 5,\n)"} {
+		stmt := parseSingleStatement(t, input)
+
+		exp, ok := stmt.Expression.(*ast.CallExpression)
+		if !ok {
+			t.Errorf("expected CallExpression, got %T", exp)
+			return
+		}
+
+		if !testIdentifier(t, exp.Function, "add") {
+			return
+		}
+
+		if len(exp.Arguments) != 3 {
+			t.Errorf("expected length of parameters to be 3, got %d", len(exp.Arguments))
+			return
+		}
+
+		testLiteralExpression(t, exp.Arguments[0], 1)
+		testInfixExpression(t, exp.Arguments[1], 2, "*", 3)
+		testInfixExpression(t, exp.Arguments[2], 4, "+", 5)
+	}
 }
 
 func TestPrefixExpressions(t *testing.T) {
@@ -550,6 +748,22 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"5 > 4 == 3 < 4 & c | b",
 			"((((5 > 4) == (3 < 4)) & c) | b)",
 		},
+		{
+			"a || b && c | d & e == f",
+			"(a || (b && (c | (d & (e == f)))))",
+		},
+		{
+			"a && b || c && d",
+			"((a && b) || (c && d))",
+		},
+		{
+			"a + b |> f(c) |> g() == d",
+			"((((a + b) |> f(c)) |> g()) == d)",
+		},
+		{
+			"x |> f(y: 1) > 2 || x |> g()",
+			"(((x |> f(y: 1)) > 2) || (x |> g()))",
+		},
 		{
 			"add(a, b, 1, 2 * 3, 4 + 5, add(6, 7 * 8))",
 			"add(a, b, 1, (2 * 3), (4 + 5), add(6, (7 * 8)))",
@@ -658,6 +872,10 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a * b[2], b[1], 2 * [1, 2][1])",
 			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
 		},
+		{
+			"-a.b.c(d) * e[f].g",
+			"((-((a.b).c)(d)) * ((e[f]).g))",
+		},
 	}
 	for _, tt := range tests {
 		program := parseSingleInputProgram(t, tt.input)
@@ -813,6 +1031,56 @@ func TestLetStatements(t *testing.T) {
 	}
 }
 
+func TestStructStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		name     string
+		expected string
+	}{
+		{"struct Person { name, age }", "Person", "struct Person { name, age }"},
+		{"struct Point {\n\tx,\n\ty,\n};", "Point", "struct Point { x, y }"},
+		{"struct Unit {}", "Unit", "struct Unit {}"},
+	}
+
+	for _, tt := range tests {
+		program := parseSingleInputProgram(t, tt.input)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("expected 1 statement, got %d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.StructStatement)
+		if !ok {
+			t.Fatalf("expected StructStatement, got %T", program.Statements[0])
+		}
+		if !testIdentifier(t, stmt.Name, tt.name) {
+			return
+		}
+		if stmt.String() != tt.expected {
+			t.Errorf("wrong struct. expected=%q, got=%q", tt.expected, stmt.String())
+		}
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"struct { a }", "expected next token to be IDENT, got { instead"},
+		{"struct P { a b }", "expected next token to be ,, got IDENT instead"},
+		{"struct P { a, 1 }", "expected next token to be IDENT, got INT instead"},
+		{"struct P { a, a }", "Error at line 1. a is a field of P more than once"},
+	}
+
+	for _, tt := range errors {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 || !strings.HasSuffix(p.Errors()[0], tt.expected) {
+			t.Errorf("wrong errors for %q. expected=%q, got=%q", tt.input, tt.expected, p.Errors())
+		}
+	}
+}
+
 func TestReturnStatements(t *testing.T) {
 	tests := []struct {
 		input         string
@@ -871,8 +1139,9 @@ func testLetStatement(t *testing.T, s ast.Statement, name string) bool {
 		return false
 	}
 
-	if letStmt.Name.Value != name {
-		t.Errorf("Expected %s, got %s", name, letStmt.Name.Value)
+	ident, ok := letStmt.Name.(*ast.Identifier)
+	if !ok || ident.Value != name {
+		t.Errorf("Expected %s, got %s", name, letStmt.Name)
 		return false
 	}
 
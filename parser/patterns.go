@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"monkey/ast"
+	"monkey/token"
+)
+
+func (p *Parser) parseMatchExpression() ast.Expression {
+	exp := &ast.MatchExpression{Token: p.currToken}
+
+	p.nextToken()
+	exp.Value = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+
+		arm := ast.MatchArm{Pattern: p.parsePattern()}
+
+		if p.peekTokenIs(token.IF) {
+			p.nextToken()
+			p.nextToken()
+			arm.Guard = p.parseExpression(LOWEST)
+		}
+
+		if !p.expectPeek(token.ARROW) {
+			return nil
+		}
+		p.nextToken()
+
+		arm.Value = p.parseExpression(LOWEST)
+		exp.Arms = append(exp.Arms, arm)
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+
+	return exp
+}
+
+func (p *Parser) parseRestElement() ast.Expression {
+	rest := &ast.RestElement{Token: p.currToken}
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	rest.Name = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+	return rest
+}
+
+// Patterns are parsed as expressions and then checked to only have what a pattern can have
+func (p *Parser) parsePattern() ast.Expression {
+	line := p.currToken.Line
+	pattern := p.parseExpression(LOWEST)
+	if pattern != nil {
+		p.checkPattern(pattern, line, map[string]bool{})
+	}
+	return pattern
+}
+
+func (p *Parser) checkPattern(pattern ast.Expression, line int, names map[string]bool) {
+	switch pattern := pattern.(type) {
+	case nil:
+		// Already reported when parsing it
+		return
+	case *ast.IntegerLiteral, *ast.StringLiteral, *ast.BooleanLiteral:
+		return
+	case *ast.PrefixExpression:
+		if _, ok := pattern.Right.(*ast.IntegerLiteral); ok && pattern.Operator == token.MINUS {
+			return
+		}
+	case *ast.Identifier:
+		p.checkName(pattern, line, names)
+		return
+	case *ast.ArrayLiteral:
+		for i, el := range pattern.Elements {
+			if rest, ok := el.(*ast.RestElement); ok && i == len(pattern.Elements)-1 {
+				p.checkName(rest.Name, line, names)
+				continue
+			}
+			p.checkPattern(el, line, names)
+		}
+		return
+	case *ast.HashLiteral:
+		for _, pair := range pattern.Pairs {
+			switch pair.Key.(type) {
+			case *ast.IntegerLiteral, *ast.StringLiteral, *ast.BooleanLiteral:
+			default:
+				p.lineError(line, "hash pattern keys must be literals, got %s", pair.Key.String())
+			}
+			p.checkPattern(pair.Value, line, names)
+		}
+		return
+	case *ast.RestElement:
+		p.lineError(line, "%s must be the last element of an array pattern", pattern.String())
+		return
+	}
+
+	p.lineError(line, "%s is not a valid pattern", pattern.String())
+}
+
+// Parses the next token as a name, or an array or hash pattern to destructure
+func (p *Parser) parseBinding() ast.Expression {
+	if p.peekTokenIs(token.LBRACKET) || p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+		return p.parsePattern()
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	return &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+}
+
+// Names can only be bound once by a pattern, _ and null bind nothing
+func (p *Parser) checkName(ident *ast.Identifier, line int, names map[string]bool) {
+	if ident.Value == "_" || ident.Value == "null" {
+		return
+	}
+	if names[ident.Value] {
+		p.lineError(line, "%s is bound more than once in the pattern", ident.Value)
+	}
+	names[ident.Value] = true
+}
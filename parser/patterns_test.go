@@ -0,0 +1,150 @@
+package parser
+
+import (
+	"monkey/ast"
+	"monkey/lexer"
+	"strings"
+	"testing"
+)
+
+func TestMatchExpression(t *testing.T) {
+	input := `match x {
+	0 => "zero",
+	-1 => "minus one",
+	[head, ...rest] if head > 0 => rest,
+	{"name": n, "tags": [_, ..._]} => n,
+	_ => null,
+}`
+
+	stmt := parseSingleStatement(t, input)
+
+	exp, ok := stmt.Expression.(*ast.MatchExpression)
+	if !ok {
+		t.Fatalf("expected MatchExpression, got %T", stmt.Expression)
+	}
+
+	if exp.Value.String() != "x" {
+		t.Errorf("wrong value. got=%q", exp.Value.String())
+	}
+
+	tests := []struct {
+		pattern string
+		guard   string
+		value   string
+	}{
+		{"0", "", "zero"},
+		{"(-1)", "", "minus one"},
+		{"[head, ...rest]", "(head > 0)", "rest"},
+		{"{name:n, tags:[_, ..._]}", "", "n"},
+		{"_", "", "null"},
+	}
+
+	if len(exp.Arms) != len(tests) {
+		t.Fatalf("expected %d arms, got %d", len(tests), len(exp.Arms))
+	}
+
+	for i, tt := range tests {
+		arm := exp.Arms[i]
+		if arm.Pattern.String() != tt.pattern {
+			t.Errorf("wrong pattern %d. expected=%q, got=%q", i, tt.pattern, arm.Pattern.String())
+		}
+		guard := ""
+		if arm.Guard != nil {
+			guard = arm.Guard.String()
+		}
+		if guard != tt.guard {
+			t.Errorf("wrong guard %d. expected=%q, got=%q", i, tt.guard, guard)
+		}
+		if arm.Value.String() != tt.value {
+			t.Errorf("wrong value %d. expected=%q, got=%q", i, tt.value, arm.Value.String())
+		}
+	}
+}
+
+func TestEmptyMatchExpression(t *testing.T) {
+	stmt := parseSingleStatement(t, "match f(x) {}")
+
+	exp, ok := stmt.Expression.(*ast.MatchExpression)
+	if !ok {
+		t.Fatalf("expected MatchExpression, got %T", stmt.Expression)
+	}
+
+	if len(exp.Arms) != 0 || exp.String() != "match f(x) {}" {
+		t.Errorf("wrong match. got=%q", exp.String())
+	}
+}
+
+func TestDestructuringLet(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, b, ...rest] = arr", "let [a, b, ...rest] = arr;"},
+		{`let {"name": n, "age": a} = person`, "let {name:n, age:a} = person;"},
+		{"let [[a, _], {1: b}] = x;", "let [[a, _], {1:b}] = x;"},
+	}
+
+	for _, tt := range tests {
+		program := parseSingleInputProgram(t, tt.input)
+		if len(program.Statements) != 1 {
+			t.Fatalf("expected 1 statement, got %d", len(program.Statements))
+		}
+		if _, ok := program.Statements[0].(*ast.LetStatement); !ok {
+			t.Fatalf("expected LetStatement, got %T", program.Statements[0])
+		}
+		if program.String() != tt.expected {
+			t.Errorf("wrong let. expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestDestructuringParameters(t *testing.T) {
+	stmt := parseSingleStatement(t, `fn(a, [b, ...c], {"d": d}) { a }`)
+
+	fn, ok := stmt.Expression.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("expected FunctionLiteral, got %T", stmt.Expression)
+	}
+
+	expected := []string{"a", "[b, ...c]", "{d:d}"}
+	if len(fn.Parameters) != len(expected) {
+		t.Fatalf("expected %d parameters, got %d", len(expected), len(fn.Parameters))
+	}
+	for i, p := range fn.Parameters {
+		if p.String() != expected[i] {
+			t.Errorf("wrong parameter %d. expected=%q, got=%q", i, expected[i], p.String())
+		}
+	}
+}
+
+func TestPatternErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"match x { a + 1 => 1 }", "Error at line 1. (a + 1) is not a valid pattern"},
+		{"match x { [...a, b] => 1 }", "Error at line 1. ...a must be the last element of an array pattern"},
+		{"match x { [a, a] => 1 }", "Error at line 1. a is bound more than once in the pattern"},
+		{"match x { {k: 1} => 1 }", "Error at line 1. hash pattern keys must be literals, got k"},
+		{"match x {\n\tf(a) => 1 }", "Error at line 2. f(a) is not a valid pattern"},
+		{"match x { [a, ...] => 1 }", "expected next token to be IDENT, got ] instead"},
+		{"match x { 1 2 }", "expected next token to be =>, got INT instead"},
+		{"let [a, a] = x", "Error at line 1. a is bound more than once in the pattern"},
+		{"let 5 = x", "expected next token to be IDENT, got INT instead"},
+		{"fn(a, [b + 1]) { a }", "Error at line 1. (b + 1) is not a valid pattern"},
+		{"fn(1) { 1 }", "expected next token to be IDENT, got INT instead"},
+		{"fn(...a, b) { b }", "Error at line 1. ...a must be the last parameter"},
+		{"fn(...a = []) { a }", "expected next token to be ), got = instead"},
+		{"f(x: 1, 2)", "Error at line 1. positional arguments must go before the named ones in f(x: 1, 2)"},
+		{"f(x: 1, x: 2)", "Error at line 1. x is passed more than once in f(x: 1, x: 2)"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 || !strings.HasSuffix(p.Errors()[0], tt.expected) {
+			t.Errorf("wrong errors for %q. expected=%q, got=%q", tt.input, tt.expected, p.Errors())
+		}
+	}
+}
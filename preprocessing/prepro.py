@@ -2,38 +2,36 @@
 # define %""" \1
 
 """
-#run do
-def generate_dataclass(name, fields)
-    puts "\nfrom dataclasses import dataclass\n\n@dataclass\nclass \#{name}:"
-    fields.split(',').each do |field|
-        name, type = field.split(':')
-        puts "    \#{name.strip}: \#{type.strip}"
-    end
-end
-#end
+#let dataclass = fn(name, fields) {
+from dataclasses import dataclass
+
+@dataclass
+class $name:
+##each(fields, fn(field) {
+    $field
+##})
+#}
 
 #define for\s+(\d+)\.\.(\d+) for i in range(\1,\2)
-#define \{$ :
-#define \}$ pass
+#define ^([^#]*)\{$ \1:
+#define ^([^#]*)\}$ \1pass
 #define fn(\s+) def\1
-#define (\w+)\s*=\s*class\s*\((.*)\) #run do\ngenerate_dataclass('\1','\2')\n#end
+#define (\w+)\s*=\s*class\s*\((.*)\) #dataclass("\1", [\2])
 #define catch\s+(.*) try:\n\t\1\nexcept:\n\tpass
 """
 
 # This is a code generated class
-Person = class (name: str, age: int, gender: str)
+Person = class ("name: str", "age: int", "gender: str")
 
 """%
-# run do
-3.times do |i|
-    generate_dataclass("Carlitos\#{i}", 'power:float,level:int')
-end
-# end
+# times(3, fn(i) {
+##dataclass("Carlitos$i", ["power: float", "level: int"])
+# })
 %"""
 
-# 3.times do |i|
-print("Test #{i}")
-# end
+# times(3, fn(i) {
+print("Test $i")
+# })
 
 
 # idk what to do
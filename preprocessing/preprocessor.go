@@ -0,0 +1,371 @@
+package preprocessing
+
+import (
+	"bytes"
+	"fmt"
+	"monkey/ast"
+	"monkey/evaluator"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"monkey/token"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Helpers available to every directive, written in Monkey itself
+const PRELUDE = `
+let times = fn(n, f) {
+	let iter = fn(i, acc) {
+		if i >= n { return acc }
+		iter(i + 1, push(acc, f(i)))
+	}
+	iter(0, [])
+}
+
+let each = fn(arr, f) {
+	let iter = fn(arr, acc) {
+		if len(arr) == 0 { return acc }
+		iter(tail(arr), push(acc, f(head(arr))))
+	}
+	iter(arr, [])
+}
+`
+
+const BLOCK = "__block"
+
+// How many times the output of substitutions is preprocessed again, reached when
+// replacements keep defining new substitutions
+const MAX_EXPANSION_DEPTH = 100
+
+var (
+	defineRegexp    = regexp.MustCompile(`^\s*#\s*define\s+(.+?)\s+(.+)$`)
+	directiveRegexp = regexp.MustCompile(`^\s*(#+)(.*)$`)
+	variableRegexp  = regexp.MustCompile(`\\?\$[a-zA-Z_][a-zA-Z0-9_]*`)
+	blockRegexp     = regexp.MustCompile(`^` + BLOCK + `(\d+)__$`)
+)
+
+var closers = map[token.TokenType]string{
+	token.LPAREN:   token.RPAREN,
+	token.LBRACE:   token.RBRACE,
+	token.LBRACKET: token.RBRACKET,
+}
+
+type definition struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// Shared between a preprocessor and the ones spawned for its blocks
+type rules struct {
+	definitions []definition
+	blocks      []string
+}
+
+// Line by line source to source preprocessor.
+//
+// Lines like `#define pattern replacement` add a regex substitution applied to
+// every following line. Any other line starting with # is a Monkey directive,
+// when its brackets are left open it starts a block that is closed by the next
+// line with the same amount of #, the lines in between are the block body.
+// The body is produced wherever the directive evaluates to it, interpolating
+// the $variables in scope and preprocessing the result again.
+type Preprocessor struct {
+	rules *rules
+	env   *object.Environment
+
+	// Definitions applied to the line being preprocessed again, they aren't applied to their own output
+	expanding map[int]bool
+	depth     int
+
+	prefix  string
+	command string
+	body    bytes.Buffer
+}
+
+func New() *Preprocessor {
+	env := object.NewEnvironment()
+	evaluator.Eval(parser.New(lexer.New(PRELUDE)).ParseProgram(), env)
+	return newPreprocessor(&rules{}, env)
+}
+
+func newPreprocessor(rules *rules, env *object.Environment) *Preprocessor {
+	return &Preprocessor{rules: rules, env: env, expanding: map[int]bool{}}
+}
+
+func (p *Preprocessor) Process(input string) (string, error) {
+	out, err := p.processLines(input)
+	if err != nil {
+		return out, err
+	}
+
+	if p.command != "" {
+		return out, fmt.Errorf("unterminated directive block %s%s", p.prefix, p.command)
+	}
+
+	return out, nil
+}
+
+func (p *Preprocessor) processLines(input string) (string, error) {
+	var out bytes.Buffer
+
+	for _, line := range strings.SplitAfter(input, "\n") {
+		if line == "" {
+			continue
+		}
+
+		processed, err := p.Preprocess(line)
+		if err != nil {
+			return out.String(), err
+		}
+		out.WriteString(processed)
+	}
+
+	return out.String(), nil
+}
+
+func (p *Preprocessor) Preprocess(line string) (string, error) {
+	applied := []int{}
+	for i, d := range p.rules.definitions {
+		if !p.expanding[i] && d.pattern.MatchString(line) {
+			line = d.pattern.ReplaceAllString(line, d.replacement)
+			applied = append(applied, i)
+		}
+	}
+
+	if len(applied) > 0 {
+		return p.expand(line, applied)
+	}
+
+	text := strings.TrimRight(line, "\r\n")
+
+	if m := defineRegexp.FindStringSubmatch(text); m != nil {
+		pattern, err := regexp.Compile("(?m)" + m[1])
+		if err != nil {
+			return "", fmt.Errorf("invalid define pattern %q: %s", m[1], err)
+		}
+		p.rules.definitions = append(p.rules.definitions, definition{pattern: pattern, replacement: unescape(m[2])})
+		return "", nil
+	}
+
+	m := directiveRegexp.FindStringSubmatch(text)
+
+	if p.command == "" && m != nil {
+		return p.directive(m[1], m[2])
+	}
+
+	if p.command != "" && m != nil && m[1] == p.prefix {
+		return p.closeBlock(m[2])
+	}
+
+	if p.command != "" {
+		if strings.TrimSpace(line) != "" {
+			p.body.WriteString(line)
+		}
+		return "", nil
+	}
+
+	return line, nil
+}
+
+// Preprocesses the output of the definitions again without them, so replacements
+// containing their own pattern like #define foo foobar don't expand forever
+func (p *Preprocessor) expand(output string, applied []int) (string, error) {
+	if p.depth == MAX_EXPANSION_DEPTH {
+		return "", fmt.Errorf("defines expanded more than %d times in %q", MAX_EXPANSION_DEPTH, strings.TrimRight(output, "\r\n"))
+	}
+
+	for _, i := range applied {
+		p.expanding[i] = true
+	}
+	p.depth++
+
+	out, err := p.processLines(output)
+
+	p.depth--
+	for _, i := range applied {
+		delete(p.expanding, i)
+	}
+	return out, err
+}
+
+func (p *Preprocessor) directive(prefix string, command string) (string, error) {
+	if closing := closingOf(command); closing != "" {
+		// Lines that aren't valid Monkey are just comments
+		if _, ok := p.parse(command, closing); ok {
+			p.prefix = prefix
+			p.command = command
+		}
+		return "", nil
+	}
+
+	program, ok := p.parse(command, "")
+	if !ok || len(program.Statements) == 0 {
+		return "", nil
+	}
+
+	result := evaluator.Eval(program, p.env)
+	switch program.Statements[len(program.Statements)-1].(type) {
+	case *ast.LetStatement, *ast.StructStatement:
+		if err, ok := result.(*object.Error); ok {
+			return "", fmt.Errorf("%s: %s", strings.TrimSpace(command), err.Message)
+		}
+		return "", nil
+	}
+
+	return p.render(command, result)
+}
+
+func (p *Preprocessor) closeBlock(closing string) (string, error) {
+	command := p.command
+	program, ok := p.parse(command, closing)
+	p.rules.blocks = append(p.rules.blocks, p.body.String())
+
+	p.prefix = ""
+	p.command = ""
+	p.body.Reset()
+
+	if !ok {
+		return "", fmt.Errorf("could not close directive %s with %s", strings.TrimSpace(command), strings.TrimSpace(closing))
+	}
+
+	return p.render(command, evaluator.Eval(program, p.env))
+}
+
+// Parses a directive with the body of the next block placed before its closing
+func (p *Preprocessor) parse(command string, closing string) (*ast.Program, bool) {
+	source := command
+	if closing != "" {
+		source += fmt.Sprintf("\nfn() { %s%d__ }\n", BLOCK, len(p.rules.blocks)) + closing
+	}
+
+	parser := parser.New(lexer.New(source))
+	program := parser.ParseProgram()
+
+	return program, len(parser.Errors()) == 0
+}
+
+func (p *Preprocessor) render(command string, obj object.Object) (string, error) {
+	switch obj := obj.(type) {
+	case *object.Error:
+		return "", fmt.Errorf("%s: %s", strings.TrimSpace(command), obj.Message)
+	case *object.String:
+		if strings.HasSuffix(obj.Value, "\n") {
+			return obj.Value, nil
+		}
+		return obj.Value + "\n", nil
+	case *object.Array:
+		var out bytes.Buffer
+		for _, e := range obj.Elements {
+			text, err := p.render(command, e)
+			if err != nil {
+				return out.String(), err
+			}
+			out.WriteString(text)
+		}
+		return out.String(), nil
+	case *object.Function:
+		id, ok := blockOf(obj)
+		if !ok {
+			return "", nil
+		}
+		block := newPreprocessor(p.rules, obj.Env)
+		return block.Process(interpolate(p.rules.blocks[id], obj.Env))
+	}
+	return "", nil
+}
+
+// Gets the block id of the functions created by parse for the directive bodies
+func blockOf(fn *object.Function) (int, bool) {
+	if len(fn.Parameters) != 0 || len(fn.Body.Statements) != 1 {
+		return 0, false
+	}
+
+	stmt, ok := fn.Body.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		return 0, false
+	}
+
+	ident, ok := stmt.Expression.(*ast.Identifier)
+	if !ok {
+		return 0, false
+	}
+
+	m := blockRegexp.FindStringSubmatch(ident.Value)
+	if m == nil {
+		return 0, false
+	}
+
+	id, err := strconv.Atoi(m[1])
+	return id, err == nil
+}
+
+// Returns the brackets needed to close the ones left open by the command
+func closingOf(command string) string {
+	stack := []token.TokenType{}
+
+	l := lexer.New(command)
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		switch tok.Type {
+		case token.LPAREN, token.LBRACE, token.LBRACKET:
+			stack = append(stack, tok.Type)
+		case token.RPAREN, token.RBRACE, token.RBRACKET:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	for i := len(stack) - 1; i >= 0; i-- {
+		out.WriteString(closers[stack[i]])
+	}
+	return out.String()
+}
+
+// Replaces $variables bound in env, \$ escapes the ones meant for nested blocks
+func interpolate(text string, env *object.Environment) string {
+	return variableRegexp.ReplaceAllStringFunc(text, func(variable string) string {
+		if variable[0] == '\\' {
+			return variable[1:]
+		}
+		if val, ok := env.Get(variable[1:]); ok {
+			return val.Inspect()
+		}
+		return variable
+	})
+}
+
+// Turns a define replacement into a regexp template, \1 being the first group
+func unescape(replacement string) string {
+	var out bytes.Buffer
+
+	for i := 0; i < len(replacement); i++ {
+		ch := replacement[i]
+
+		if ch == '$' {
+			out.WriteString("$$")
+			continue
+		}
+
+		if ch != '\\' || i+1 == len(replacement) {
+			out.WriteByte(ch)
+			continue
+		}
+
+		i++
+		switch next := replacement[i]; {
+		case next == 'n':
+			out.WriteByte('\n')
+		case next == 't':
+			out.WriteByte('\t')
+		case next >= '0' && next <= '9':
+			out.WriteString("${" + string(next) + "}")
+		default:
+			out.WriteByte(next)
+		}
+	}
+
+	return out.String()
+}
@@ -0,0 +1,116 @@
+package preprocessing
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDefinitions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"#define print\\( echo(\nprint(1)\n", "echo(1)\n"},
+		{"#define (\\w+)!$ \\1()\nmain!\n", "main()\n"},
+		{"#define catch\\s+(.*) try:\\n\\t\\1\ncatch f()\n", "try:\n\tf()\n"},
+		{"#define cost 5$\nlet price = cost\n", "let price = 5$\n"},
+		{"#define a b\n#define b c\na\n", "c\n"},
+		{"#define foo foobar\nfoo()\n", "foobar()\n"},
+		{"#define foo foo.bar\n#define bar foo\nbar\n", "foo.bar.bar\n"},
+		{"print(1)\n", "print(1)\n"},
+	}
+
+	for _, tt := range tests {
+		testPreprocess(t, tt.input, tt.expected)
+	}
+}
+
+func TestDirectives(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"#times(2, fn(i) {\nlet a$i = $i\n#})\n", "let a0 = 0\nlet a1 = 1\n"},
+		{"#if true {\nyes\n#}\n#if false {\nno\n#}\n", "yes\n"},
+		{"#let name = \"monkey\"\nhi $name\n#if true {\nhi $name\n#}\n", "hi $name\nhi monkey\n"},
+		{"#each([\"a\", \"b\"], fn(x) {\n##times(2, fn(y) {\n$x$y \\$x\n##})\n#})\n", "a0 a\na1 a\nb0 b\nb1 b\n"},
+		{"#let twice = fn(x) {\n$x $x\n#}\n#twice(\"hey\")\n", "hey hey\n"},
+		{"#\"raw string\"\n", "raw string\n"},
+		{"# just a comment\nline\n", "line\n"},
+	}
+
+	for _, tt := range tests {
+		testPreprocess(t, tt.input, tt.expected)
+	}
+}
+
+func TestDirectiveErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"#times(2, fn(i) {\nline\n", "unterminated directive block #times(2, fn(i) {"},
+		{"#define ( x\n", "invalid define pattern \"(\": error parsing regexp: missing closing ): `(?m)(`"},
+		{"#if true {\nline\n#) + 1\n", "could not close directive if true { with ) + 1"},
+		{"#times(2, fn(i) {\nline\n#}) + true\n", "times(2, fn(i) {: Operation + between ARRAY and BOOLEAN not implemented!"},
+	}
+
+	for _, tt := range tests {
+		_, err := New().Process(tt.input)
+		if err == nil {
+			t.Errorf("expected error %q for %q", tt.expected, tt.input)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, err.Error())
+		}
+	}
+}
+
+func TestExamples(t *testing.T) {
+	tests := []struct {
+		file     string
+		expected string
+	}{
+		{
+			"prepro.mky",
+			"\n\nlet text = \"\nTest 0\nTest 1\nTest 2\n\";\n\necho(text);\n\nlet list = [\n\t\"Test 0\",\n\t\"Test 1\",\n\t\"Test 2\",\n]\n",
+		},
+		{
+			"example.mky",
+			"\n" +
+				"let main1 = fn() {\n\techo(\"running function \", \"main\", 1)\n" +
+				"\t\tlet n = 0 + 1\n\t\techo(\"hi there \", n, \"!\")\n}\n" +
+				"let main2 = fn() {\n\techo(\"running function \", \"main\", 2)\n" +
+				"\t\tlet n = 0 + 1\n\t\techo(\"hi there \", n, \"!\")\n" +
+				"\t\tlet n = 1 + 1\n\t\techo(\"hi there \", n, \"!\")\n}\n" +
+				"let main3 = fn() {\n\techo(\"running function \", \"main\", 3)\n" +
+				"\t\tlet n = 0 + 1\n\t\techo(\"hi there \", n, \"!\")\n" +
+				"\t\tlet n = 1 + 1\n\t\techo(\"hi there \", n, \"!\")\n" +
+				"\t\tlet n = 2 + 1\n\t\techo(\"hi there \", n, \"!\")\n}\n" +
+				"\nmain1()\nmain2()\nmain3()\n",
+		},
+	}
+
+	for _, tt := range tests {
+		text, err := os.ReadFile(tt.file)
+		if err != nil {
+			t.Fatalf("could not read %s: %s", tt.file, err)
+		}
+		testPreprocess(t, string(text), tt.expected)
+	}
+}
+
+func testPreprocess(t *testing.T, input string, expected string) bool {
+	output, err := New().Process(input)
+	if err != nil {
+		t.Errorf("unexpected error for %q: %s", input, err)
+		return false
+	}
+
+	if output != expected {
+		t.Errorf("wrong output for %q. expected=%q, got=%q", input, expected, output)
+		return false
+	}
+	return true
+}
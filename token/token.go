@@ -1,11 +1,15 @@
 package token
 
+import "sort"
+
 const (
 	ILLEGAL = "ILLEGAL"
 	EOF     = "EOF"
 
 	STRING   = "STRING"
 	TEMPLATE = "TEMPLATE"
+	COMMENT  = "COMMENT"
+	DOC      = "DOC"
 	IDENT    = "IDENT"
 	INT      = "INT"
 
@@ -18,6 +22,8 @@ const (
 	PERCENT  = "%"
 	BANG     = "!"
 	COLON    = ":"
+	ARROW    = "=>"
+	ELLIPSIS = "..."
 
 	LT  = "<"
 	GT  = ">"
@@ -25,11 +31,17 @@ const (
 	GE  = ">="
 	EQ  = "=="
 	NE  = "!="
-	AND = "&"
-	OR  = "|"
+	AND = "&&"
+	OR  = "||"
+
+	BIT_AND = "&"
+	BIT_OR  = "|"
+
+	PIPE = "|>"
 
 	// Delimiters
 	COMMA     = ","
+	DOT       = "."
 	SEMICOLON = ";"
 
 	LPAREN   = "("
@@ -48,6 +60,13 @@ const (
 	TRUE     = "TRUE"
 	ELSE     = "ELSE"
 	IF       = "IF"
+	MATCH    = "MATCH"
+	STRUCT   = "STRUCT"
+	WHILE    = "WHILE"
+	FOR      = "FOR"
+	IN       = "IN"
+	BREAK    = "BREAK"
+	CONTINUE = "CONTINUE"
 )
 
 type TokenType string
@@ -59,14 +78,31 @@ type Token struct {
 }
 
 var keywords = map[string]TokenType{
-	"fn":     FUNCTION,
-	"let":    LET,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
-	"true":   TRUE,
-	"false":  FALSE,
-	"macro":  MACRO,
+	"fn":       FUNCTION,
+	"let":      LET,
+	"if":       IF,
+	"else":     ELSE,
+	"return":   RETURN,
+	"true":     TRUE,
+	"false":    FALSE,
+	"macro":    MACRO,
+	"match":    MATCH,
+	"struct":   STRUCT,
+	"while":    WHILE,
+	"for":      FOR,
+	"in":       IN,
+	"break":    BREAK,
+	"continue": CONTINUE,
+}
+
+// The keywords sorted
+func Keywords() []string {
+	names := []string{}
+	for name := range keywords {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 func LookupIdent(ident string) TokenType {
@@ -0,0 +1,89 @@
+package runtime
+
+import (
+	"monkey/evaluator"
+	"monkey/object"
+)
+
+// Matches the value appending the values it binds, nil when it doesn't match
+type Pattern func(value object.Object, bound []object.Object) []object.Object
+
+// Binds the value to a name
+func Bind(value object.Object, bound []object.Object) []object.Object {
+	return append(bound, value)
+}
+
+// Matches anything, like _
+func Wildcard(value object.Object, bound []object.Object) []object.Object {
+	return bound
+}
+
+func Literal(literal object.Object) Pattern {
+	return func(value object.Object, bound []object.Object) []object.Object {
+		if !evaluator.MatchesLiteral(literal, value) {
+			return nil
+		}
+		return bound
+	}
+}
+
+// Matches arrays with as many elements as patterns, or more when there is a rest pattern
+func ArrayPattern(rest Pattern, elements ...Pattern) Pattern {
+	return func(value object.Object, bound []object.Object) []object.Object {
+		arr, ok := value.(*object.Array)
+		if !ok || len(arr.Elements) < len(elements) || rest == nil && len(arr.Elements) != len(elements) {
+			return nil
+		}
+
+		for i, el := range elements {
+			if bound = el(arr.Elements[i], bound); bound == nil {
+				return nil
+			}
+		}
+
+		if rest != nil {
+			remaining := append([]object.Object{}, arr.Elements[len(elements):]...)
+			return rest(&object.Array{Elements: remaining}, bound)
+		}
+		return bound
+	}
+}
+
+// Matches hashes having every key with a value matching its pattern
+func HashPattern(keys []object.Object, values ...Pattern) Pattern {
+	return func(value object.Object, bound []object.Object) []object.Object {
+		hash, ok := value.(*object.Hash)
+		if !ok {
+			return nil
+		}
+
+		for i, key := range keys {
+			pair, ok := hash.Pairs[key.(object.Hashable).HashKey()]
+			if !ok {
+				return nil
+			}
+			if bound = values[i](pair.Value, bound); bound == nil {
+				return nil
+			}
+		}
+		return bound
+	}
+}
+
+// Returns the values the pattern binds in order, nil when the value doesn't match
+func Match(pattern Pattern, value object.Object) []object.Object {
+	return pattern(value, []object.Object{})
+}
+
+// Like Match for lets and parameters, failing when the value doesn't match the source pattern
+func Destructure(pattern Pattern, value object.Object, source string) []object.Object {
+	bound := Match(pattern, value)
+	if bound == nil {
+		check(&object.Error{Message: value.Inspect() + " does not match " + source})
+	}
+	return bound
+}
+
+func Unmatched(value object.Object) object.Object {
+	return check(&object.Error{Message: "no pattern matches " + value.Inspect()})
+}
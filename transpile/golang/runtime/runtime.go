@@ -0,0 +1,161 @@
+// Helpers used by the Go code generated from Monkey programs.
+//
+// Errors stop the generated code by panicking, the functions recover them to
+// return them as values like the evaluator does.
+package runtime
+
+import (
+	"fmt"
+	"monkey/evaluator"
+	"monkey/object"
+)
+
+var (
+	TRUE  = evaluator.TRUE
+	FALSE = evaluator.FALSE
+	NULL  = evaluator.NULL
+
+	BREAK    = evaluator.BREAK
+	CONTINUE = evaluator.CONTINUE
+)
+
+func check(obj object.Object) object.Object {
+	if err, ok := obj.(*object.Error); ok {
+		panic(err)
+	}
+	return obj
+}
+
+func Infix(operator string, left object.Object, right object.Object) object.Object {
+	return check(evaluator.Infix(operator, left, right))
+}
+
+func Prefix(operator string, right object.Object) object.Object {
+	return check(evaluator.Prefix(operator, right))
+}
+
+func Index(left object.Object, index object.Object) object.Object {
+	return check(evaluator.Index(left, index))
+}
+
+func Call(fn object.Object, args ...object.Object) object.Object {
+	return check(evaluator.Call(fn, args...))
+}
+
+// Calls with the last len(names) arguments passed by name
+func CallNamed(fn object.Object, names []string, args ...object.Object) object.Object {
+	return check(evaluator.CallNamed(fn, args, names))
+}
+
+func Member(receiver object.Object, name string) object.Object {
+	return check(evaluator.Member(receiver, name))
+}
+
+// Calls the method name of the receiver, env has the builtins it may dispatch to
+func CallMethod(env *object.Environment, receiver object.Object, name string, names []string, args ...object.Object) object.Object {
+	return check(evaluator.CallMethod(receiver, name, args, names, env))
+}
+
+// Builds a hash from its keys and values one after the other
+func Hash(pairs ...object.Object) object.Object {
+	hash := object.NewHash()
+
+	for i := 0; i+1 < len(pairs); i += 2 {
+		key, value := pairs[i], pairs[i+1]
+
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			panic(&object.Error{Message: fmt.Sprintf("%T=(%v) not yet implemented as hash key!", key, key)})
+		}
+		hash.Set(hashKey.HashKey(), object.HashPair{Key: key, Value: value})
+	}
+
+	return hash
+}
+
+func Truthy(obj object.Object) bool {
+	return evaluator.IsTruthy(obj)
+}
+
+// The right side of && only runs when the left one is truthy
+func And(left object.Object, right func() object.Object) object.Object {
+	if !Truthy(left) {
+		return left
+	}
+	return right()
+}
+
+// The right side of || only runs when the left one is falsy
+func Or(left object.Object, right func() object.Object) object.Object {
+	if Truthy(left) {
+		return left
+	}
+	return right()
+}
+
+// Gets the value of a name the program doesn't define, from the host or the builtins
+func Lookup(env *object.Environment, name string) object.Object {
+	if val, ok := env.Get(name); ok {
+		return val
+	}
+	if builtin := evaluator.Builtin(name, env); builtin != nil {
+		return builtin
+	}
+	return NULL
+}
+
+// Deferred by every function to turn the errors and returns into its result
+func Recover(result *object.Object) {
+	switch r := recover().(type) {
+	case nil:
+	case *object.Error:
+		*result = r
+	case *object.Return:
+		*result = r.Value
+	default:
+		panic(r)
+	}
+}
+
+// Runs the body while the condition is true, see Iteration for the result
+func While(condition func() object.Object, body func() object.Object) object.Object {
+	values := []object.Object{}
+	for Truthy(condition()) {
+		if _, stop := evaluator.Iteration(iterate(body), &values); stop {
+			break
+		}
+	}
+	return &object.Array{Elements: values}
+}
+
+// Runs the body with each element of the iterable, see Iteration for the result
+func For(iterable object.Object, body func(object.Object) object.Object) object.Object {
+	elements, err := evaluator.Elements(iterable)
+	if err != nil {
+		panic(err)
+	}
+
+	values := []object.Object{}
+	for _, element := range elements {
+		if _, stop := evaluator.Iteration(iterate(func() object.Object { return body(element) }), &values); stop {
+			break
+		}
+	}
+	return &object.Array{Elements: values}
+}
+
+// Runs an iteration, turning the breaks and continues panicking from inside of expressions into its result
+func iterate(body func() object.Object) (result object.Object) {
+	defer func() {
+		switch r := recover().(type) {
+		case nil:
+		case *object.Break:
+			result = r
+		case *object.Continue:
+			result = r
+		default:
+			panic(r)
+		}
+	}()
+	return body()
+}
@@ -0,0 +1,792 @@
+package golang
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"monkey/ast"
+	"monkey/evaluator"
+	"monkey/token"
+	"monkey/transpile"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var reserved = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true, "default": true, "defer": true,
+	"else": true, "fallthrough": true, "for": true, "func": true, "go": true, "goto": true, "if": true,
+	"import": true, "interface": true, "map": true, "package": true, "range": true, "return": true,
+	"select": true, "struct": true, "switch": true, "type": true, "var": true, "_": true,
+	// Used by the generated code
+	"panic": true, "string": true, "object": true, "runtime": true, "fmt": true, "os": true, "env": true, "args": true, "result": true,
+	"subject": true, "bound": true, "destructured": true, "element": true,
+}
+
+// Names bound by a Monkey function, each one is a Go variable of the function
+type scope struct {
+	outer    *scope
+	declared map[string]bool
+	used     map[string]bool
+}
+
+func newScope(outer *scope) *scope {
+	return &scope{outer: outer, declared: map[string]bool{}, used: map[string]bool{}}
+}
+
+type Transpiler struct {
+	out   bytes.Buffer
+	scope *scope
+	// Ifs, matches and loop bodies used as values are function literals called in place
+	nesting transpile.Nesting
+	// Names the program uses without defining them, looked up in the environment
+	globals map[string]bool
+	errors  []string
+}
+
+func New() *Transpiler {
+	return &Transpiler{globals: map[string]bool{}}
+}
+
+// Returns a Go file with a Run function executing the program, pkg main adds a main running it
+func Transpile(program *ast.Program, pkg string) (string, error) {
+	run, err := New().TranspileProgram(program, "Run")
+	if err != nil {
+		return "", err
+	}
+
+	var out bytes.Buffer
+
+	out.WriteString("// Code generated by monkey to-go. DO NOT EDIT.\n\n")
+	out.WriteString("package " + pkg + "\n\n")
+
+	if pkg == "main" {
+		out.WriteString("import (\n\"fmt\"\n\"monkey/object\"\n\"monkey/transpile/golang/runtime\"\n\"os\"\n)\n\n")
+		out.WriteString("func main() {\nif err, ok := Run(object.NewEnvironment()).(*object.Error); ok {\nif err.Exit {\nos.Exit(err.Code)\n}\nfmt.Fprintln(os.Stderr, err.Inspect())\nos.Exit(1)\n}\n}\n\n")
+	} else {
+		out.WriteString("import (\n\"monkey/object\"\n\"monkey/transpile/golang/runtime\"\n)\n\n")
+	}
+
+	out.WriteString(run)
+
+	code, err := format.Source(out.Bytes())
+	if err != nil {
+		return "", err
+	}
+	return string(code), nil
+}
+
+// Returns a function with the given name running the program, its top level
+// lets are set in the environment it gets so the host can use them
+func (t *Transpiler) TranspileProgram(program *ast.Program, name string) (string, error) {
+	t.scope = newScope(nil)
+	lets := declarations(program.Statements)
+	for _, l := range lets {
+		t.scope.declared[l] = true
+	}
+
+	body := t.nested(func() {
+		t.statements(program.Statements, true)
+	})
+
+	if len(t.errors) != 0 {
+		return "", fmt.Errorf("%s", strings.Join(t.errors, "\n"))
+	}
+
+	var out bytes.Buffer
+
+	out.WriteString(fmt.Sprintf("func %s(env *object.Environment) (result object.Object) {\n", name))
+	out.WriteString("defer runtime.Recover(&result)\n")
+
+	globals := []string{}
+	for g := range t.globals {
+		globals = append(globals, g)
+	}
+	sort.Strings(globals)
+
+	for _, name := range append(globals, lets...) {
+		out.WriteString(fmt.Sprintf("%s := runtime.Lookup(env, %q)\n", mangle(name), name))
+	}
+	out.WriteString(body)
+	out.WriteString("}\n")
+
+	code, err := format.Source(out.Bytes())
+	if err != nil {
+		return "", err
+	}
+	return string(code), nil
+}
+
+func (t *Transpiler) errorf(format string, a ...any) string {
+	t.errors = append(t.errors, fmt.Sprintf(format, a...))
+	return "runtime.NULL"
+}
+
+func (t *Transpiler) line(format string, a ...any) {
+	t.out.WriteString(fmt.Sprintf(format, a...))
+	t.out.WriteString("\n")
+}
+
+// Returns the Go code write adds instead of adding it, for the body of a function literal
+func (t *Transpiler) nested(write func()) string {
+	out := t.out
+	t.out = bytes.Buffer{}
+
+	write()
+
+	code := t.out.String()
+	t.out = out
+	return code
+}
+
+func (t *Transpiler) statements(stmts []ast.Statement, tail bool) {
+	if transpile.Statements(stmts, tail, t.statement) {
+		t.line("return runtime.NULL")
+	}
+}
+
+// The value of a tail statement is returned from the function literal it is in
+func (t *Transpiler) statement(stmt ast.Statement, tail bool) {
+	switch stmt := stmt.(type) {
+	case *ast.LetStatement:
+		value := t.expression(stmt.Value)
+		ident, ok := stmt.Name.(*ast.Identifier)
+		if !ok {
+			t.line("{")
+			t.line("destructured := %s", value)
+			t.destructure(stmt.Name, "destructured")
+			if tail {
+				t.line("return destructured")
+			}
+			t.line("}")
+			return
+		}
+
+		t.assign(ident.Value, value, tail)
+	case *ast.StructStatement:
+		fields := []string{}
+		for _, f := range stmt.Fields {
+			fields = append(fields, f.Value)
+		}
+		t.assign(stmt.Name.Value, fmt.Sprintf("&object.StructType{Name: %q, Fields: %s}", stmt.Name.Value, quoteAll(fields)), tail)
+	case *ast.BreakStatement:
+		t.loopControl("runtime.BREAK")
+	case *ast.ContinueStatement:
+		t.loopControl("runtime.CONTINUE")
+	case *ast.ReturnStatement:
+		value := t.expression(stmt.RetValue)
+		if t.nesting.UnwindsReturn() {
+			t.line("panic(&object.Return{Value: %s})", value)
+		} else {
+			t.line("return %s", value)
+		}
+	case *ast.ExpressionStatement:
+		if exp, ok := stmt.Expression.(*ast.IfExpression); ok {
+			t.ifStatement(exp, tail)
+			return
+		}
+
+		exp := t.expression(stmt.Expression)
+		if tail {
+			t.line("return %s", exp)
+		} else {
+			t.line("_ = %s", exp)
+		}
+	default:
+		t.errorf("transpiling %T to Go is not supported", stmt)
+	}
+}
+
+// Panics with the break or continue when it must unwind, runtime.While and runtime.For recover it
+func (t *Transpiler) loopControl(control string) {
+	if t.nesting.UnwindsLoopControl() {
+		t.line("panic(%s)", control)
+	} else {
+		t.line("return %s", control)
+	}
+}
+
+// Sets the variable of the name, which is global at the top level
+func (t *Transpiler) assign(name string, value string, tail bool) {
+	t.line("%s = %s", mangle(name), value)
+	if t.scope.outer == nil {
+		t.line("env.Set(%q, %s)", name, mangle(name))
+	}
+	if tail {
+		t.line("return %s", mangle(name))
+	}
+}
+
+func (t *Transpiler) ifStatement(exp *ast.IfExpression, tail bool) {
+	for i, branch := range exp.Branches {
+		if i == 0 {
+			t.line("if runtime.Truthy(%s) {", t.expression(branch.Condition))
+		} else {
+			t.line("} else if runtime.Truthy(%s) {", t.expression(branch.Condition))
+		}
+		t.statements(branch.Consequence.Statements, tail)
+	}
+
+	if exp.Alternative != nil {
+		t.line("} else {")
+		t.statements(exp.Alternative.Statements, tail)
+		t.line("}")
+		return
+	}
+
+	t.line("}")
+	if tail {
+		t.line("return runtime.NULL")
+	}
+}
+
+func (t *Transpiler) expression(exp ast.Expression) string {
+	switch exp := exp.(type) {
+	case *ast.Identifier:
+		return t.identifier(exp.Value)
+	case *ast.IntegerLiteral:
+		return fmt.Sprintf("&object.Integer{Value: %d}", exp.Value)
+	case *ast.BooleanLiteral:
+		if exp.Value {
+			return "runtime.TRUE"
+		}
+		return "runtime.FALSE"
+	case *ast.StringLiteral:
+		return fmt.Sprintf("&object.String{Value: %s}", strconv.Quote(exp.Value))
+	case *ast.TemplateString:
+		return t.template(exp)
+	case *ast.PrefixExpression:
+		return fmt.Sprintf("runtime.Prefix(%q, %s)", exp.Operator, t.expression(exp.Right))
+	case *ast.InfixExpression:
+		switch exp.Operator {
+		case token.AND:
+			return fmt.Sprintf("runtime.And(%s, func() object.Object { return %s })", t.expression(exp.Left), t.expression(exp.Right))
+		case token.OR:
+			return fmt.Sprintf("runtime.Or(%s, func() object.Object { return %s })", t.expression(exp.Left), t.expression(exp.Right))
+		}
+		return fmt.Sprintf("runtime.Infix(%q, %s, %s)", exp.Operator, t.expression(exp.Left), t.expression(exp.Right))
+	case *ast.IndexExpression:
+		return fmt.Sprintf("runtime.Index(%s, %s)", t.expression(exp.Left), t.expression(exp.Index))
+	case *ast.MemberExpression:
+		return fmt.Sprintf("runtime.Member(%s, %q)", t.expression(exp.Left), exp.Name.Value)
+	case *ast.CallExpression:
+		if ident, ok := exp.Function.(*ast.Identifier); ok && (ident.Value == "quote" || ident.Value == "unquote") {
+			return t.errorf("%s is only available inside of macros expanded before transpiling", ident.Value)
+		}
+		values, names := exp.SplitArguments()
+		if member, ok := exp.Function.(*ast.MemberExpression); ok {
+			args := []string{"env", t.expression(member.Left), strconv.Quote(member.Name.Value), quoteAll(names)}
+			args = append(args, t.expressions(values)...)
+			return fmt.Sprintf("runtime.CallMethod(%s)", strings.Join(args, ", "))
+		}
+		args := []string{t.expression(exp.Function)}
+		if len(names) > 0 {
+			args = append(args, quoteAll(names))
+		}
+		args = append(args, t.expressions(values)...)
+		if len(names) > 0 {
+			return fmt.Sprintf("runtime.CallNamed(%s)", strings.Join(args, ", "))
+		}
+		return fmt.Sprintf("runtime.Call(%s)", strings.Join(args, ", "))
+	case *ast.NamedArgument:
+		return t.errorf("%s is only allowed in calls", exp.String())
+	case *ast.ArrayLiteral:
+		return fmt.Sprintf("&object.Array{Elements: []object.Object{%s}}", strings.Join(t.expressions(exp.Elements), ", "))
+	case *ast.HashLiteral:
+		return t.hash(exp)
+	case *ast.IfExpression:
+		return t.ifExpression(exp)
+	case *ast.WhileExpression:
+		condition := t.expression(exp.Condition)
+		body := t.loopBody(func() {
+			t.statements(exp.Body.Statements, true)
+		})
+		return fmt.Sprintf("runtime.While(func() object.Object { return %s }, func() object.Object {\n%s})", condition, body)
+	case *ast.ForExpression:
+		iterable := t.expression(exp.Iterable)
+		body := t.loopBody(func() {
+			if ident, ok := exp.Name.(*ast.Identifier); ok {
+				t.assign(ident.Value, "element", false)
+			} else {
+				t.destructure(exp.Name, "element")
+			}
+			t.statements(exp.Body.Statements, true)
+		})
+		return fmt.Sprintf("runtime.For(%s, func(element object.Object) object.Object {\n%s})", iterable, body)
+	case *ast.MatchExpression:
+		return t.match(exp)
+	case *ast.RestElement:
+		return t.errorf("%s is only allowed in patterns", exp.String())
+	case *ast.DefaultParameter:
+		return t.errorf("%s is only allowed in parameters", exp.String())
+	case *ast.FunctionLiteral:
+		return t.function(exp)
+	case *ast.MacroLiteral:
+		return t.macro(exp)
+	case *ast.AstMacroLiteral:
+		return t.errorf("macro %s must be defined with a top level let to be expanded", exp.String())
+	case nil:
+		return t.errorf("missing expression")
+	}
+	return t.errorf("transpiling %T to Go is not supported", exp)
+}
+
+func (t *Transpiler) expressions(exps []ast.Expression) []string {
+	out := []string{}
+	for _, e := range exps {
+		out = append(out, t.expression(e))
+	}
+	return out
+}
+
+func (t *Transpiler) identifier(name string) string {
+	for s := t.scope; s != nil; s = s.outer {
+		if s.declared[name] {
+			s.used[name] = true
+			return mangle(name)
+		}
+	}
+
+	if name == "null" {
+		return "runtime.NULL"
+	}
+
+	t.globals[name] = true
+	return mangle(name)
+}
+
+func (t *Transpiler) hash(exp *ast.HashLiteral) string {
+	pairs := []string{}
+	for _, pair := range exp.Pairs {
+		pairs = append(pairs, t.expression(pair.Key), t.expression(pair.Value))
+	}
+
+	return "runtime.Hash(" + strings.Join(pairs, ", ") + ")"
+}
+
+func (t *Transpiler) template(exp *ast.TemplateString) string {
+	parts := []string{}
+	for _, e := range exp.Elements {
+		switch e := e.(type) {
+		case *ast.StringLiteral:
+			parts = append(parts, strconv.Quote(e.Value))
+		default:
+			parts = append(parts, t.expression(e)+".Inspect()")
+		}
+	}
+
+	if len(parts) == 0 {
+		parts = append(parts, `""`)
+	}
+
+	return "&object.String{Value: " + strings.Join(parts, " + ") + "}"
+}
+
+// The body of a loop is a function literal returning the value of an iteration
+func (t *Transpiler) loopBody(write func()) string {
+	return t.nesting.LoopBody(func() string {
+		return t.nested(write)
+	})
+}
+
+func (t *Transpiler) ifExpression(exp *ast.IfExpression) string {
+	body := t.nesting.Expression(func() string {
+		return t.nested(func() {
+			t.ifStatement(exp, true)
+		})
+	})
+
+	return "func() object.Object {\n" + body + "}()"
+}
+
+// Each arm binds the names of its pattern in a block of its own
+func (t *Transpiler) match(exp *ast.MatchExpression) string {
+	subject := t.expression(exp.Value)
+
+	body := t.nesting.Expression(func() string {
+		return t.nested(func() {
+			t.line("subject := %s", subject)
+			for _, arm := range exp.Arms {
+				names := []string{}
+				pattern := t.pattern(arm.Pattern, &names)
+
+				t.scope = newScope(t.scope)
+				for _, name := range names {
+					t.scope.declared[name] = true
+				}
+				guard := ""
+				if arm.Guard != nil {
+					guard = t.expression(arm.Guard)
+				}
+				value := t.expression(arm.Value)
+				s := t.scope
+				t.scope = s.outer
+
+				t.line("if bound := runtime.Match(%s, subject); bound != nil {", pattern)
+				for i, name := range names {
+					t.line("%s := bound[%d]", mangle(name), i)
+					if !s.used[name] {
+						t.line("_ = %s", mangle(name))
+					}
+				}
+				if guard != "" {
+					t.line("if runtime.Truthy(%s) {", guard)
+					t.line("return %s", value)
+					t.line("}")
+				} else {
+					t.line("return %s", value)
+				}
+				t.line("}")
+			}
+			t.line("return runtime.Unmatched(subject)")
+		})
+	})
+
+	return "func() object.Object {\n" + body + "}()"
+}
+
+// Assigns the names of the pattern from the value, they are declared like the names of lets
+func (t *Transpiler) destructure(pattern ast.Expression, value string) {
+	names := []string{}
+	p := t.pattern(pattern, &names)
+	if len(names) == 0 {
+		t.line("runtime.Destructure(%s, %s, %q)", p, value, pattern.String())
+		return
+	}
+
+	t.line("bound := runtime.Destructure(%s, %s, %q)", p, value, pattern.String())
+	for i, name := range names {
+		t.line("%s = bound[%d]", mangle(name), i)
+		if t.scope.outer == nil {
+			t.line("env.Set(%q, %s)", name, mangle(name))
+		}
+	}
+}
+
+// Returns a runtime.Pattern for the pattern, adding the names it binds in order
+func (t *Transpiler) pattern(pattern ast.Expression, names *[]string) string {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		switch pattern.Value {
+		case "_":
+			return "runtime.Wildcard"
+		case "null":
+			return "runtime.Literal(runtime.NULL)"
+		}
+		*names = append(*names, pattern.Value)
+		return "runtime.Bind"
+	case *ast.ArrayLiteral:
+		rest := "nil"
+		elements := []string{}
+		for _, el := range pattern.Elements {
+			if r, ok := el.(*ast.RestElement); ok {
+				rest = t.pattern(r.Name, names)
+				continue
+			}
+			elements = append(elements, t.pattern(el, names))
+		}
+		return fmt.Sprintf("runtime.ArrayPattern(%s)", strings.Join(append([]string{rest}, elements...), ", "))
+	case *ast.HashLiteral:
+		keys := []string{}
+		values := []string{}
+		for _, pair := range pattern.Pairs {
+			keys = append(keys, t.expression(pair.Key))
+			values = append(values, t.pattern(pair.Value, names))
+		}
+		return fmt.Sprintf("runtime.HashPattern([]object.Object{%s}, %s)", strings.Join(keys, ", "), strings.Join(values, ", "))
+	}
+	return fmt.Sprintf("runtime.Literal(%s)", t.expression(pattern))
+}
+
+func (t *Transpiler) function(exp *ast.FunctionLiteral) string {
+	params := []string{}
+	bound := []string{}
+	for _, p := range exp.Parameters {
+		if ident, ok := p.(*ast.Identifier); ok {
+			params = append(params, ident.Value)
+			continue
+		}
+		// The others are set before the body, after taking their default values
+		params = append(params, "")
+		bound = append(bound, bindings(parameterTarget(p))...)
+	}
+
+	header, body := t.scoped(params, bound, declarations(exp.Body.Statements), func() {
+		for i, p := range exp.Parameters {
+			if params[i] != "" {
+				continue
+			}
+			arg := fmt.Sprintf("args[%d]", i)
+			if def, ok := p.(*ast.DefaultParameter); ok {
+				t.line("if %s == nil {", arg)
+				t.line("%s = %s", arg, t.expression(def.Value))
+				t.line("}")
+			}
+			if ident, ok := parameterTarget(p).(*ast.Identifier); ok {
+				t.line("%s = %s", mangle(ident.Value), arg)
+				continue
+			}
+			t.line("{")
+			t.destructure(parameterTarget(p), arg)
+			t.line("}")
+		}
+		t.statements(exp.Body.Statements, true)
+	})
+
+	signature := []string{}
+	for _, p := range evaluator.Signature(exp.Parameters) {
+		fields := []string{"Source: " + strconv.Quote(p.Source)}
+		if p.Name != "" {
+			fields = append(fields, "Name: "+strconv.Quote(p.Name))
+		}
+		if p.Default {
+			fields = append(fields, "Default: true")
+		}
+		if p.Rest {
+			fields = append(fields, "Rest: true")
+		}
+		signature = append(signature, "{"+strings.Join(fields, ", ")+"}")
+	}
+
+	return fmt.Sprintf("&object.CompiledFunction{Parameters: []object.Parameter{%s}, Body: %s, Fn: func(args ...object.Object) (result object.Object) {\ndefer runtime.Recover(&result)\n%s%s}}",
+		strings.Join(signature, ", "), strconv.Quote(exp.Body.String()), header, body)
+}
+
+func (t *Transpiler) macro(exp *ast.MacroLiteral) string {
+	params := []string{}
+	for _, p := range exp.Parameters {
+		params = append(params, p.Value)
+	}
+
+	patterns := t.expressions(exp.Pattern)
+
+	header, body := t.scoped(params, nil, nil, func() {
+		t.line("return %s", t.template(exp.Body))
+	})
+
+	return fmt.Sprintf("&object.CompiledMacro{Parameters: %s, Patterns: []object.Object{%s}, Body: %s, Fn: func(args ...object.Object) object.Object {\n%s%s}}",
+		quoteAll(params), strings.Join(patterns, ", "), strconv.Quote(exp.Body.String()), header, body)
+}
+
+// Compiles the body of a function in its own scope, returning the declaration of its variables too,
+// bound are the names of the parameter patterns
+func (t *Transpiler) scoped(params []string, bound []string, lets []string, write func()) (string, string) {
+	outer := t.scope
+	t.scope = newScope(outer)
+	s := t.scope
+
+	for _, p := range append(params, bound...) {
+		s.declared[p] = true
+	}
+	for _, l := range lets {
+		s.declared[l] = true
+	}
+
+	body := t.nesting.Function(func() string {
+		return t.nested(write)
+	})
+	t.scope = outer
+
+	var header bytes.Buffer
+	assigned := map[string]bool{}
+
+	for i, p := range params {
+		if !s.used[p] && !contains(lets, p) {
+			continue
+		}
+		if assigned[p] {
+			header.WriteString(fmt.Sprintf("%s = args[%d]\n", mangle(p), i))
+		} else {
+			header.WriteString(fmt.Sprintf("%s := args[%d]\n", mangle(p), i))
+		}
+		assigned[p] = true
+	}
+
+	for _, b := range bound {
+		if !assigned[b] {
+			header.WriteString(fmt.Sprintf("var %s object.Object\n", mangle(b)))
+			assigned[b] = true
+		}
+		if !s.used[b] {
+			header.WriteString(fmt.Sprintf("_ = %s\n", mangle(b)))
+		}
+	}
+
+	for _, l := range lets {
+		if !assigned[l] {
+			if s.used[l] {
+				// Until the let runs the name means what it means outside
+				header.WriteString(fmt.Sprintf("%s := %s\n", mangle(l), t.identifier(l)))
+			} else {
+				header.WriteString(fmt.Sprintf("var %s object.Object\n", mangle(l)))
+			}
+		}
+		if !s.used[l] {
+			header.WriteString(fmt.Sprintf("_ = %s\n", mangle(l)))
+		}
+	}
+
+	return header.String(), body
+}
+
+// Names bound by lets in the statements, without the ones of nested functions
+func declarations(stmts []ast.Statement) []string {
+	names := []string{}
+	seen := map[string]bool{}
+
+	declare := func(bound ...string) {
+		for _, name := range bound {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	var statement func(ast.Statement)
+	var expression func(ast.Expression)
+
+	statement = func(stmt ast.Statement) {
+		switch stmt := stmt.(type) {
+		case *ast.LetStatement:
+			declare(bindings(stmt.Name)...)
+			expression(stmt.Value)
+		case *ast.StructStatement:
+			declare(stmt.Name.Value)
+		case *ast.ReturnStatement:
+			expression(stmt.RetValue)
+		case *ast.ExpressionStatement:
+			expression(stmt.Expression)
+		}
+	}
+
+	expression = func(exp ast.Expression) {
+		switch exp := exp.(type) {
+		case *ast.IfExpression:
+			for _, branch := range exp.Branches {
+				expression(branch.Condition)
+				for _, s := range branch.Consequence.Statements {
+					statement(s)
+				}
+			}
+			if exp.Alternative != nil {
+				for _, s := range exp.Alternative.Statements {
+					statement(s)
+				}
+			}
+		case *ast.WhileExpression:
+			expression(exp.Condition)
+			for _, s := range exp.Body.Statements {
+				statement(s)
+			}
+		case *ast.ForExpression:
+			declare(bindings(exp.Name)...)
+			expression(exp.Iterable)
+			for _, s := range exp.Body.Statements {
+				statement(s)
+			}
+		case *ast.MatchExpression:
+			expression(exp.Value)
+			for _, arm := range exp.Arms {
+				expression(arm.Guard)
+				expression(arm.Value)
+			}
+		case *ast.PrefixExpression:
+			expression(exp.Right)
+		case *ast.InfixExpression:
+			expression(exp.Left)
+			expression(exp.Right)
+		case *ast.IndexExpression:
+			expression(exp.Left)
+			expression(exp.Index)
+		case *ast.MemberExpression:
+			expression(exp.Left)
+		case *ast.CallExpression:
+			expression(exp.Function)
+			for _, a := range exp.Arguments {
+				expression(a)
+			}
+		case *ast.NamedArgument:
+			expression(exp.Value)
+		case *ast.ArrayLiteral:
+			for _, e := range exp.Elements {
+				expression(e)
+			}
+		case *ast.HashLiteral:
+			for _, pair := range exp.Pairs {
+				expression(pair.Key)
+				expression(pair.Value)
+			}
+		}
+	}
+
+	for _, stmt := range stmts {
+		statement(stmt)
+	}
+	return names
+}
+
+// Name or pattern a parameter binds, without its default value or ...
+func parameterTarget(param ast.Expression) ast.Expression {
+	switch param := param.(type) {
+	case *ast.DefaultParameter:
+		return param.Parameter
+	case *ast.RestElement:
+		return param.Name
+	}
+	return param
+}
+
+// Names bound by a let or parameter, in the order its pattern binds them
+func bindings(target ast.Expression) []string {
+	if ident, ok := target.(*ast.Identifier); ok {
+		return []string{ident.Value}
+	}
+
+	names := []string{}
+	var pattern func(ast.Expression)
+	pattern = func(exp ast.Expression) {
+		switch exp := exp.(type) {
+		case *ast.Identifier:
+			if exp.Value != "_" && exp.Value != "null" {
+				names = append(names, exp.Value)
+			}
+		case *ast.RestElement:
+			pattern(exp.Name)
+		case *ast.ArrayLiteral:
+			for _, el := range exp.Elements {
+				pattern(el)
+			}
+		case *ast.HashLiteral:
+			for _, pair := range exp.Pairs {
+				pattern(pair.Value)
+			}
+		}
+	}
+	pattern(target)
+	return names
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func quoteAll(names []string) string {
+	quoted := []string{}
+	for _, n := range names {
+		quoted = append(quoted, strconv.Quote(n))
+	}
+	return "[]string{" + strings.Join(quoted, ", ") + "}"
+}
+
+func mangle(name string) string {
+	if reserved[name] {
+		return name + "_"
+	}
+	return name
+}
@@ -0,0 +1,136 @@
+package golang
+
+import (
+	"bytes"
+	"fmt"
+	"monkey/lexer"
+	"monkey/parser"
+	"monkey/transpile/transpiletest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Same as inspect for the generated program
+const INSPECT = `
+func inspect(obj object.Object) string {
+	if obj == nil {
+		return "nil"
+	}
+	return obj.Inspect()
+}
+`
+
+// Runs the inputs of the evaluator tests compiled to Go comparing them with the evaluator results
+func TestEvaluatorInputs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("building the generated code is slow")
+	}
+
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not installed")
+	}
+
+	inputs := transpiletest.EvaluatorInputs(t)
+
+	var code bytes.Buffer
+	code.WriteString("package main\n\nimport (\n\t\"fmt\"\n\t\"monkey/object\"\n\t\"monkey/transpile/golang/runtime\"\n)\n\n")
+	code.WriteString("var _ = runtime.NULL\n\n")
+	code.WriteString(INSPECT)
+
+	expected := []string{}
+	calls := []string{}
+
+	for i, input := range inputs {
+		run, err := New().TranspileProgram(transpiletest.Parse(t, input), fmt.Sprintf("Run%d", i))
+		if err != nil {
+			t.Errorf("could not transpile %q: %s", input, err)
+			continue
+		}
+		code.WriteString(run + "\n")
+
+		expected = append(expected, transpiletest.Evaluate(t, input))
+		calls = append(calls, fmt.Sprintf("\tfmt.Printf(\"%%q\\n\", inspect(Run%d(object.NewEnvironment())))\n", i))
+	}
+
+	code.WriteString("func main() {\n" + strings.Join(calls, "") + "}\n")
+
+	// A file outside of the module still builds with its packages when run from inside
+	main := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(main, code.Bytes(), 0644); err != nil {
+		t.Fatalf("could not write the program: %s", err)
+	}
+
+	output, err := exec.Command(goBin, "run", main).CombinedOutput()
+	if err != nil {
+		t.Fatalf("could not run the program: %s\n%s", err, output)
+	}
+
+	results := strings.Split(strings.TrimSuffix(string(output), "\n"), "\n")
+	if len(results) != len(expected) {
+		t.Fatalf("wrong number of results. expected=%d, got=%d", len(expected), len(results))
+	}
+
+	for i, result := range results {
+		got, err := strconv.Unquote(result)
+		if err != nil {
+			t.Fatalf("could not read result %q: %s", result, err)
+		}
+		if got != expected[i] {
+			t.Errorf("wrong result for %q. expected=%q, got=%q", inputs[i], expected[i], got)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"quote(1 + 2)", "quote is only available inside of macros expanded before transpiling"},
+		{"let m = macro(x) { quote(unquote(x)) }; let f = fn() { m }", "macro macro(x) quote(unquote(x)) must be defined with a top level let to be expanded"},
+	}
+
+	for _, tt := range tests {
+		_, err := New().TranspileProgram(parser.New(lexer.New(tt.input)).ParseProgram(), "Run")
+		if err == nil {
+			t.Errorf("expected error %q for %q", tt.expected, tt.input)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, err.Error())
+		}
+	}
+}
+
+func TestScopes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let x = 1; x", []string{"x := runtime.Lookup(env, \"x\")", "x = &object.Integer{Value: 1}", "env.Set(\"x\", x)", "return x"}},
+		{"len([])", []string{"len := runtime.Lookup(env, \"len\")", "return runtime.Call(len, &object.Array{Elements: []object.Object{}})"}},
+		{"fn(x, y) { y }", []string{"y := args[1]", "return y"}},
+		{"let x = 1; fn() { let y = x; let x = 2; y }", []string{"x := x", "y = x", "x = &object.Integer{Value: 2}"}},
+		{"let string = 1; let map = 2", []string{"string_ = &object.Integer{Value: 1}", "map_ = &object.Integer{Value: 2}"}},
+		{"fn() { if true { return 1 } }", []string{"if runtime.Truthy(runtime.TRUE) {", "return &object.Integer{Value: 1}", "return runtime.NULL"}},
+		{"fn() { let a = if true { return 1 } }", []string{"panic(&object.Return{Value: &object.Integer{Value: 1}})"}},
+	}
+
+	for _, tt := range tests {
+		code, err := New().TranspileProgram(parser.New(lexer.New(tt.input)).ParseProgram(), "Run")
+		if err != nil {
+			t.Errorf("could not transpile %q: %s", tt.input, err)
+			continue
+		}
+		for _, line := range tt.expected {
+			if !strings.Contains(code, line) {
+				t.Errorf("missing %q transpiling %q. got=\n%s", line, tt.input, code)
+			}
+		}
+	}
+}
@@ -0,0 +1,565 @@
+const $ = (() => {
+	class MonkeyError extends Error {}
+
+	class Return {
+		constructor(value) {
+			this.value = value;
+		}
+	}
+
+	class Hash {
+		constructor(pairs) {
+			this.pairs = new Map();
+			for (const [key, value] of pairs) {
+				this.pairs.set(hashKey(key), [key, value]);
+			}
+		}
+	}
+
+	class Struct {
+		constructor(of, values) {
+			this.of = of;
+			this.values = values;
+		}
+
+		get(field) {
+			const i = this.of.fields.indexOf(field);
+			return i === -1 ? undefined : this.values[i];
+		}
+	}
+
+	const error = (message) => new MonkeyError(message);
+
+	const type = (obj) => {
+		if (obj === null || obj === undefined) return "NULL";
+		if (typeof obj === "number") return "INTEGER";
+		if (typeof obj === "string") return "STRING";
+		if (typeof obj === "boolean") return "BOOLEAN";
+		if (Array.isArray(obj)) return "ARRAY";
+		if (obj instanceof Hash) return "HASH";
+		if (obj instanceof Struct) return "STRUCT";
+		if (obj.fields) return "STRUCT_TYPE";
+		if (obj.macro) return "MACRO";
+		if (obj.builtin) return "BUILTIN";
+		if (typeof obj === "function") return "FUNCTION";
+		return "UNKNOWN";
+	};
+
+	// The name of the struct for struct values
+	const typeName = (obj) => type(obj) === "STRUCT" ? obj.of.structName : type(obj);
+
+	const hashKey = (key) => {
+		switch (type(key)) {
+			case "INTEGER":
+			case "STRING":
+			case "BOOLEAN":
+				return type(key) + ":" + key;
+		}
+		throw error(`${type(key)}=(${inspect(key)}) not yet implemented as hash key!`);
+	};
+
+	const inspect = (obj) => {
+		switch (type(obj)) {
+			case "NULL":
+				return "null";
+			case "ARRAY":
+				return "[" + obj.map(inspect).join(", ") + "]";
+			case "HASH":
+				return "{" + [...obj.pairs.values()].map(([k, v]) => inspect(k) + ":" + inspect(v)).join(", ") + "}";
+			case "STRUCT":
+				return obj.of.structName + "{" + obj.of.fields.map((f, i) => f + ": " + (type(obj.values[i]) === "STRING" ? JSON.stringify(obj.values[i]) : inspect(obj.values[i]))).join(", ") + "}";
+			case "BUILTIN":
+				return "builtin function";
+			case "FUNCTION":
+			case "MACRO":
+			case "STRUCT_TYPE":
+				return obj.source;
+		}
+		return String(obj);
+	};
+
+	const infix = (op, left, right) => {
+		const lt = type(left);
+		const rt = type(right);
+
+		if (lt === "INTEGER" && rt === "INTEGER") {
+			switch (op) {
+				case "+": return left + right;
+				case "-": return left - right;
+				case "*": return left * right;
+				case "/": return Math.trunc(left / right);
+				case "%": return left % right;
+				case "&": return Number(BigInt(left) & BigInt(right));
+				case "|": return Number(BigInt(left) | BigInt(right));
+				case "<": return left < right;
+				case ">": return left > right;
+				case "<=": return left <= right;
+				case ">=": return left >= right;
+				case "==": return left === right;
+				case "!=": return left !== right;
+			}
+		} else if (lt === "BOOLEAN" && rt === "BOOLEAN") {
+			switch (op) {
+				case "==": return left === right;
+				case "!=": return left !== right;
+				case "&": return left && right;
+				case "|": return left || right;
+			}
+		} else if (lt === "STRING" && rt === "STRING") {
+			switch (op) {
+				case "+": return left + right;
+				case "-": return left.split(right).join("");
+				case "==": return left === right;
+				case "!=": return left !== right;
+			}
+		} else if (lt === "INTEGER" && rt === "STRING") {
+			switch (op) {
+				case "+": return left + right;
+				case "*": return right.repeat(Math.max(left, 0));
+			}
+		} else if (lt === "STRING" && rt === "INTEGER") {
+			switch (op) {
+				case "+": return left + right;
+				case "*": return left.repeat(Math.max(right, 0));
+			}
+		} else if (lt === "STRUCT" && rt === "STRUCT") {
+			switch (op) {
+				case "==": return structsEqual(left, right);
+				case "!=": return !structsEqual(left, right);
+			}
+		} else {
+			switch (op) {
+				case "==": return left === right;
+				case "!=": return left !== right;
+			}
+		}
+
+		throw error(`Operation ${op} between ${lt} and ${rt} not implemented!`);
+	};
+
+	// Same struct type and fields with equal values
+	const structsEqual = (left, right) => left.of === right.of && left.values.every((v, i) => valuesEqual(v, right.values[i]));
+
+	// Arrays element by element, hashes by the values of the same keys, other values by ==
+	const valuesEqual = (left, right) => {
+		if (type(left) !== type(right)) return false;
+		switch (type(left)) {
+			case "ARRAY":
+				return left.length === right.length && left.every((v, i) => valuesEqual(v, right[i]));
+			case "HASH":
+				return left.pairs.size === right.pairs.size && [...left.pairs].every(([key, [, v]]) => right.pairs.has(key) && valuesEqual(v, right.pairs.get(key)[1]));
+			case "STRUCT":
+				return structsEqual(left, right);
+			case "NULL":
+				return true;
+		}
+		return left === right;
+	};
+
+	const prefix = (op, right) => {
+		switch (op) {
+			case "!":
+				return !truthy(right);
+			case "-":
+				if (type(right) === "INTEGER") return -right;
+				throw error(`Not implemented ${op} for ${type(right)}`);
+		}
+		throw error(`unknown operator: ${op}`);
+	};
+
+	// Same rules as the evaluator, null, false, 0 and empty strings, arrays and hashes are false
+	const truthy = (cond) => {
+		switch (type(cond)) {
+			case "NULL": return false;
+			case "BOOLEAN": return cond;
+			case "INTEGER": return cond !== 0;
+			case "STRING": return cond !== "";
+			case "ARRAY": return cond.length > 0;
+			case "HASH": return cond.pairs.size > 0;
+		}
+		return true;
+	};
+
+	const and = (left, right) => truthy(left) ? right() : left;
+
+	const or = (left, right) => truthy(left) ? left : right();
+
+	// Patterns push the values they bind into bound, returning null when the value doesn't match
+	const bind = (value, bound) => {
+		bound.push(value);
+		return bound;
+	};
+
+	const wildcard = (value, bound) => bound;
+
+	const literal = (lit) => (value, bound) => type(value) === type(lit) && value === lit ? bound : null;
+
+	const arrayPattern = (rest, ...elements) => (value, bound) => {
+		if (type(value) !== "ARRAY" || value.length < elements.length) return null;
+		if (rest === null && value.length !== elements.length) return null;
+		for (let i = 0; i < elements.length; i++) {
+			if (elements[i](value[i], bound) === null) return null;
+		}
+		return rest === null ? bound : rest(value.slice(elements.length), bound);
+	};
+
+	const hashPattern = (keys, ...values) => (value, bound) => {
+		if (type(value) !== "HASH") return null;
+		for (let i = 0; i < keys.length; i++) {
+			const pair = value.pairs.get(hashKey(keys[i]));
+			if (pair === undefined || values[i](pair[1], bound) === null) return null;
+		}
+		return bound;
+	};
+
+	const match = (pattern, value) => pattern(value, []);
+
+	// Like match for lets and parameters, failing when the value doesn't match the source pattern
+	const destructure = (pattern, value, source) => {
+		const bound = match(pattern, value);
+		if (bound === null) throw error(`${inspect(value)} does not match ${source}`);
+		return bound;
+	};
+
+	const unmatched = (value) => {
+		throw error(`no pattern matches ${inspect(value)}`);
+	};
+
+	const index = (left, idx) => {
+		if (type(left) === "ARRAY") {
+			if (type(idx) !== "INTEGER") throw error(`indexing by ${type(idx)} is not yet supported`);
+			return idx < 0 || idx >= left.length ? null : left[idx];
+		}
+		if (type(left) === "HASH") {
+			let key;
+			try {
+				key = hashKey(idx);
+			} catch (e) {
+				throw error(`indexing by ${type(idx)} is not yet supported`);
+			}
+			const pair = left.pairs.get(key);
+			return pair === undefined ? null : pair[1];
+		}
+		throw error(`indexing not supported for ${type(left)} yet`);
+	};
+
+	// Params say how each parameter takes its argument, as {name, default, rest}
+	const fn = (source, params, f) => {
+		f.source = source;
+		f.params = params;
+		return f;
+	};
+
+	// Struct types construct their values when called
+	const struct = (name, fields) => {
+		const s = (...values) => new Struct(s, values);
+		s.structName = name;
+		s.fields = fields;
+		s.params = fields.map((name) => ({ name }));
+		s.source = fields.length === 0 ? `struct ${name} {}` : `struct ${name} { ${fields.join(", ")} }`;
+		return s;
+	};
+
+	const callee = (f) => type(f) === "STRUCT_TYPE" ? `struct ${f.structName}` : `function ${f.source}`;
+
+	// Same as the evaluator, puts the arguments, the last names.length of them named, in the order of the
+	// parameters, undefined for the ones taking their default value and the remaining positional ones
+	// in an array for a rest parameter
+	const arrange = (f, args, names) => {
+		const positional = args.slice(0, args.length - names.length);
+		const arranged = Array.from({ length: f.params.length });
+
+		let fixed = f.params.length;
+		if (fixed > 0 && f.params[fixed - 1].rest) {
+			fixed--;
+			arranged[fixed] = positional.slice(fixed);
+		} else if (positional.length > fixed) {
+			throw error(`${callee(f)} takes at most ${fixed} arguments, got ${positional.length}`);
+		}
+		for (let i = 0; i < fixed && i < positional.length; i++) {
+			arranged[i] = positional[i];
+		}
+
+		names.forEach((name, i) => {
+			const j = f.params.findIndex((p, j) => j < fixed && p.name === name);
+			if (j === -1) throw error(`${callee(f)} has no parameter ${name}`);
+			if (arranged[j] !== undefined) throw error(`${callee(f)} got ${name} more than once`);
+			arranged[j] = args[positional.length + i];
+		});
+
+		const missing = f.params.filter((p, j) => j < fixed && arranged[j] === undefined && !p.default).length;
+		if (missing > 0) throw error(`${callee(f)} is missing ${missing} parameters`);
+		return arranged;
+	};
+
+	const call = (f, ...args) => {
+		switch (type(f)) {
+			case "BUILTIN":
+			case "MACRO":
+				return f(...args);
+			case "FUNCTION":
+			case "STRUCT_TYPE":
+				return f(...arrange(f, args, []));
+		}
+		throw error(`${type(f)} callable not supported yet`);
+	};
+
+	// Calls with the last names.length arguments passed by name
+	const callNamed = (f, names, ...args) => {
+		if (type(f) === "FUNCTION" || type(f) === "STRUCT_TYPE") return f(...arrange(f, args, names));
+		throw error(`${inspect(f)} doesn't take named arguments`);
+	};
+
+	// receiver.name on a Map of pairs or a struct, a missing key is null but a missing field throws
+	const member = (receiver, name) => {
+		if (type(receiver) === "STRUCT") {
+			const value = receiver.get(name);
+			if (value === undefined) throw error(`${receiver.of.structName} has no field ${name}`);
+			return value;
+		}
+		if (type(receiver) !== "HASH") throw error(`${type(receiver)} has no property ${name}`);
+		const pair = receiver.pairs.get(hashKey(name));
+		return pair === undefined ? null : pair[1];
+	};
+
+	// Calls the function of a hash under the key name or in the field name of a struct, or else the builtin called name,
+	// passing the receiver as the first argument
+	const callMethod = (receiver, name, names, ...args) => {
+		let method;
+		if (type(receiver) === "HASH") {
+			const pair = receiver.pairs.get(hashKey(name));
+			if (pair !== undefined) method = pair[1];
+		}
+		if (type(receiver) === "STRUCT") method = receiver.get(name);
+		if (method === undefined && ["STRING", "ARRAY", "HASH", "STRUCT"].includes(type(receiver)) && Object.hasOwn(builtins, name)) {
+			method = builtins[name];
+		}
+		if (method === undefined) throw error(`${typeName(receiver)} has no method ${name}`);
+		return names.length > 0 ? callNamed(method, names, receiver, ...args) : call(method, receiver, ...args);
+	};
+
+	// Loop bodies return these, or throw them from inside of expressions
+	const BREAK = { control: "break" };
+	const CONTINUE = { control: "continue" };
+
+	// Adds the value of an iteration to the values unless it continued, true when the loop breaks
+	const iterate = (body, values) => {
+		let value;
+		try {
+			value = body();
+		} catch (e) {
+			if (e !== BREAK && e !== CONTINUE) throw e;
+			value = e;
+		}
+		if (value === BREAK) return true;
+		if (value !== CONTINUE) values.push(value === undefined ? null : value);
+		return false;
+	};
+
+	// Loops result in the array of the values of the iterations that didn't continue or break
+	const whileLoop = (condition, body) => {
+		const values = [];
+		while (truthy(condition())) {
+			if (iterate(body, values)) break;
+		}
+		return values;
+	};
+
+	const forLoop = (iterable, body) => {
+		const values = [];
+		for (const element of elements(iterable)) {
+			if (iterate(() => body(element), values)) break;
+		}
+		return values;
+	};
+
+	// Copies what $.for goes through into an array, so the body can't change it, keys in insertion order
+	const elements = (iterable) => {
+		switch (type(iterable)) {
+			case "ARRAY":
+				return [...iterable];
+			case "HASH":
+				return [...iterable.pairs.values()].map(([key]) => key);
+			case "STRING":
+				return [...iterable];
+		}
+		throw error(`${type(iterable)} is not iterable`);
+	};
+
+	const caught = (e) => {
+		if (e instanceof Return) return e.value;
+		throw e;
+	};
+
+	// Prints the errors of Monkey like the interpreter does, stopping the script
+	const report = (e) => {
+		if (!(e instanceof MonkeyError)) throw e;
+		if (e.exit === undefined) console.error(e.message);
+		if (typeof process !== "undefined") process.exitCode = e.exit === undefined ? 1 : e.exit;
+	};
+
+	const builtin = (f) => {
+		f.builtin = true;
+		return f;
+	};
+
+	const arity = (name, args, want) => {
+		if (args.length !== want) {
+			throw error(`wrong number of arguments. got=${args.length}, want=${want}`);
+		}
+	};
+
+	const matcher = (name, regexp) => builtin((...args) => {
+		if (args.length !== 1) throw error(`wrong number of arguments for ${name}. got=${args.length}, want=1`);
+		if (type(args[0]) === "STRING" && regexp.test(args[0])) return args[0];
+		throw error(`argument to \`${name}\` not matched, got ${type(args[0])}`);
+	});
+
+	// Inspected like the interpreter does, each name followed by ":" and its pattern
+	const macro = (code, names, patterns, body) => {
+		const m = (...args) => {
+			if (args.length !== 1) {
+				throw error(`wrong number of arguments. got=${args.length}, want=1 string template`);
+			}
+
+			const vars = {};
+			let input = args[0];
+			if (type(input) === "STRING") {
+				names.forEach((name, i) => {
+					const pattern = patterns[i];
+					let text = "";
+					let temp = "";
+					let j = 0;
+					let matched = false;
+					const chars = [...input];
+
+					for (j = 0; j < chars.length; j++) {
+						if (type(pattern) === "BUILTIN") {
+							temp = text + chars[j];
+							try {
+								text = inspect(pattern(temp));
+								continue;
+							} catch (e) {
+								if (!(e instanceof MonkeyError)) throw e;
+							}
+						} else if (type(pattern) === "STRING") {
+							temp += chars[j];
+							if (pattern !== temp) continue;
+							text = temp;
+							j++;
+						}
+						matched = true;
+						break;
+					}
+					// Like ranging in Go, the index stays at the last character
+					if (!matched && chars.length > 0) j = chars.length - 1;
+
+					input = chars.slice(j).join("");
+					vars[name] = text;
+				});
+			}
+
+			return body(...names.map((name) => vars[name]));
+		};
+		m.macro = true;
+		m.source = `macro(${names.flatMap((name, i) => [name, ":", inspect(patterns[i])]).join(", ")}) {\n${code}\n}`;
+		return m;
+	};
+
+	// Strings are measured and split in UTF-8 bytes like Go does, a byte alone is the character of its code
+	const utf8 = new TextEncoder();
+	const bytes = (text) => utf8.encode(text);
+	const byteAt = (text, i) => String.fromCharCode(bytes(text)[i]);
+	const fromBytes = (b) => new TextDecoder().decode(b);
+
+	const builtins = {
+		int: builtin((...args) => {
+			arity("int", args, 1);
+			if (type(args[0]) === "STRING") {
+				if (/^[+-]?\d+$/.test(args[0])) return parseInt(args[0], 10);
+				throw error(`could not parse "${args[0]}" as integer`);
+			}
+			if (type(args[0]) === "INTEGER") return args[0];
+			throw error(`argument to \`int\` not supported yet, got ${type(args[0])}`);
+		}),
+		ident: matcher("ident", /^[a-zA-Z_]+$/),
+		space: matcher("space", /^[\s]+$/),
+		idents: matcher("idents", /^[a-zA-Z_ ]+$/),
+		len: builtin((...args) => {
+			arity("len", args, 1);
+			if (type(args[0]) === "STRING") return bytes(args[0]).length;
+			if (type(args[0]) === "ARRAY") return args[0].length;
+			throw error(`argument to \`len\` not supported, got ${type(args[0])}`);
+		}),
+		head: builtin((...args) => {
+			arity("head", args, 1);
+			if (type(args[0]) === "ARRAY") return args[0].length === 0 ? null : args[0][0];
+			if (type(args[0]) === "STRING") return args[0] === "" ? null : byteAt(args[0], 0);
+			throw error(`head is not implemented for ${type(args[0])}`);
+		}),
+		last: builtin((...args) => {
+			arity("last", args, 1);
+			if (type(args[0]) === "ARRAY") return args[0].length === 0 ? null : args[0][args[0].length - 1];
+			if (type(args[0]) === "STRING") return args[0] === "" ? null : byteAt(args[0], bytes(args[0]).length - 1);
+			throw error(`last is not implemented for ${type(args[0])}`);
+		}),
+		tail: builtin((...args) => {
+			arity("tail", args, 1);
+			if (type(args[0]) === "ARRAY") return args[0].slice(1);
+			if (type(args[0]) === "STRING") return args[0] === "" ? null : fromBytes(bytes(args[0]).slice(1));
+			throw error(`tail is not implemented for ${type(args[0])}`);
+		}),
+		push: builtin((...args) => {
+			arity("push", args, 2);
+			if (type(args[0]) === "ARRAY") return [...args[0], args[1]];
+			throw error(`push is not implemented for ${type(args[0])}`);
+		}),
+		string: builtin((...args) => args.map(inspect).join("")),
+		range: builtin((...args) => {
+			if (args.length !== 1 && args.length !== 2) {
+				throw error(`wrong number of arguments. got=${args.length}, want=1 or 2`);
+			}
+			for (const arg of args) {
+				if (type(arg) !== "INTEGER") throw error(`argument to \`range\` not supported, got ${type(arg)}`);
+			}
+			const [start, end] = args.length === 1 ? [0, args[0]] : args;
+			return Array.from({ length: Math.max(end - start, 0) }, (_, i) => start + i);
+		}),
+		type: builtin((...args) => {
+			arity("type", args, 1);
+			return typeName(args[0]);
+		}),
+		echo: builtin((...args) => {
+			console.log(args.map(inspect).join(""));
+			return null;
+		}),
+		raw: builtin((...args) => JSON.stringify(args.map(inspect).join(""))),
+		read: builtin(() => {
+			throw error("read is not supported in JavaScript");
+		}),
+		eval: builtin(() => {
+			throw error("eval is not supported in JavaScript");
+		}),
+		args: builtin(() => (typeof process !== "undefined" ? process.argv.slice(2) : [])),
+		assert: builtin((...args) => {
+			if (args.length === 0) throw error("wrong number of arguments. got=0, want=1 or more");
+			if (truthy(args[0])) return null;
+			const message = args.slice(1).map(inspect).join("");
+			throw error(message === "" ? "assertion failed" : `assertion failed: ${message}`);
+		}),
+		exit: builtin((...args) => {
+			if (args.length > 1) throw error(`wrong number of arguments. got=${args.length}, want=0 or 1`);
+			if (args.length === 1 && type(args[0]) !== "INTEGER") {
+				throw error(`argument to \`exit\` must be INTEGER, got ${type(args[0])}`);
+			}
+			const e = error(`exit status ${args.length === 1 ? args[0] : 0}`);
+			e.exit = args.length === 1 ? args[0] : 0;
+			throw e;
+		}),
+		emit: builtin(() => {
+			throw error("emit is only available when generating files with `monkey gen`");
+		}),
+	};
+
+	return { Hash, Return, builtins, inspect, infix, prefix, truthy, and, or, bind, wildcard, literal, arrayPattern, hashPattern, match, destructure, unmatched, index, member, struct, fn, call, callNamed, callMethod, BREAK, CONTINUE, while: whileLoop, for: forLoop, caught, report, macro };
+})();
@@ -0,0 +1,29 @@
+var assert = $.builtins.assert;
+var echo = $.builtins.echo;
+var head = $.builtins.head;
+var int = $.builtins.int;
+var last = $.builtins.last;
+var len = $.builtins.len;
+var push = $.builtins.push;
+var raw = $.builtins.raw;
+var string = $.builtins.string;
+var tail = $.builtins.tail;
+
+var name = "monkey";
+var numbers = [1, 2, $.infix("*", 3, 4), $.prefix("-", 5)];
+var ages = new $.Hash([["alice", 25], [1, "one"], [true, false]]);
+$.call(echo, name, " ", $.call(len, name));
+$.call(echo, numbers, " ", $.index(numbers, 2), " ", $.index(numbers, 10));
+$.call(echo, $.index(ages, "alice"), " ", $.index(ages, 1), " ", $.index(ages, true), " ", $.index(ages, "bob"));
+$.call(echo, $.infix("-", "abcde", "abc"), " ", $.infix("*", 3, "ab"), " ", $.infix("/", 7, 2), " ", $.infix("%", 7, 2));
+$.call(echo, $.prefix("!", true), " ", $.prefix("!", 0), " ", $.infix("<", 1, 2), " ", $.infix("==", "a", "a"), " ", $.infix("==", null, null));
+$.call(echo, $.or(0, () => 2), " ", $.and(null, () => 1), " ", $.and(false, () => $.infix("+", 1, true)), " ", $.infix("&", 6, 3), " ", $.infix("|", 6, 3), " ", $.infix("&", true, false));
+$.call(echo, $.prefix("!", ""), " ", $.prefix("!", []), " ", $.prefix("!", new $.Hash([])), " ", $.prefix("!", $.prefix("-", 1)), " ", $.or("", () => "x"), " ", (() => {
+	if ($.truthy($.prefix("-", 1))) {
+		return "yes";
+	}
+	return null;
+})());
+$.call(echo, $.call(head, numbers), " ", $.call(last, numbers), " ", $.call(tail, numbers), " ", $.call(push, numbers, 6));
+$.call(echo, $.call(string, 1, true, null), " ", $.infix("+", $.call(int, "42"), 1), " ", $.call(raw, "say hi"));
+$.call(echo, $.call(assert, "x"), " ", $.call(assert, [1]), " ", $.call(assert, $.prefix("-", 1)));
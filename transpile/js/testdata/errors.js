@@ -0,0 +1,5 @@
+var echo = $.builtins.echo;
+
+$.call(echo, "before");
+$.call(echo, $.infix("+", 1, true));
+$.call(echo, "after");
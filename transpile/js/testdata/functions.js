@@ -0,0 +1,171 @@
+var echo = $.builtins.echo;
+var head = $.builtins.head;
+var last = $.builtins.last;
+var len = $.builtins.len;
+var push = $.builtins.push;
+var range = $.builtins.range;
+var string = $.builtins.string;
+var tail = $.builtins.tail;
+var type = $.builtins.type;
+
+var map = $.fn("fn(arr, f) {\nlet iter = fn(arr, acc) if (len(arr) == 0) return acciter(tail(arr), push(acc, f(head(arr))));iter(arr, [])\n}", [{name: "arr"}, {name: "f"}], function (arr, f) {
+	var iter = $.fn("fn(arr, acc) {\nif (len(arr) == 0) return acciter(tail(arr), push(acc, f(head(arr))))\n}", [{name: "arr"}, {name: "acc"}], function (arr, acc) {
+		if ($.truthy($.infix("==", $.call(len, arr), 0))) {
+			return acc;
+		}
+		return $.call(iter, $.call(tail, arr), $.call(push, acc, $.call(f, $.call(head, arr))));
+	});
+	return $.call(iter, arr, []);
+});
+var adder = $.fn("fn(x) {\nfn(y) (x + y)\n}", [{name: "x"}], function (x) {
+	return $.fn("fn(y) {\n(x + y)\n}", [{name: "y"}], function (y) {
+		return $.infix("+", x, y);
+	});
+});
+var addTwo = $.call(adder, 2);
+$.call(echo, $.call(map, [1, 2, 3], addTwo));
+$.call(echo, $.call(map, [1, 2, 3], $.fn("fn(x) {\n(x * x)\n}", [{name: "x"}], function (x) {
+	return $.infix("*", x, x);
+})));
+var max = $.fn("fn(a, b) {\nif (a > b) a else b\n}", [{name: "a"}, {name: "b"}], function (a, b) {
+	if ($.truthy($.infix(">", a, b))) {
+		return a;
+	} else {
+		return b;
+	}
+});
+$.call(echo, $.call(max, 3, 7), " ", $.call(max, 9, 2));
+var sign = $.fn("fn(x) {\nlet label = if (x > 0) positive else if (x < 0) return negative;if (label == null) zero else label\n}", [{name: "x"}], function (x) {
+	try {
+		var label = (() => {
+			if ($.truthy($.infix(">", x, 0))) {
+				return "positive";
+			} else {
+				if ($.truthy($.infix("<", x, 0))) {
+					throw new $.Return("negative");
+				}
+				return null;
+			}
+		})();
+		if ($.truthy($.infix("==", label, null))) {
+			return "zero";
+		} else {
+			return label;
+		}
+	} catch (e) {
+		return $.caught(e);
+	}
+});
+$.call(echo, $.call(sign, 5), " ", $.call(sign, $.prefix("-", 5)), " ", $.call(sign, 0));
+var grade = $.fn("fn(n) {\nif (n > 8) A else if (n > 5) B else if (n > 2) C\n}", [{name: "n"}], function (n) {
+	if ($.truthy($.infix(">", n, 8))) {
+		return "A";
+	} else if ($.truthy($.infix(">", n, 5))) {
+		return "B";
+	} else if ($.truthy($.infix(">", n, 2))) {
+		return "C";
+	}
+	return null;
+});
+$.call(echo, $.call(grade, 9), " ", $.call(grade, 6), " ", $.call(grade, 3), " ", $.call(grade, 1));
+var describe = $.fn("fn(x) {\nmatch x { 0 => zero, [] => empty, [h, ...t] if (h > 0) => (list  + string(len(t))), {name:n} => n, null => nothing, _ => other }\n}", [{name: "x"}], function (x) {
+	return (($subject) => {
+		let $bound;
+		if (($bound = $.match($.literal(0), $subject)) !== null) {
+			return "zero";
+		}
+		if (($bound = $.match($.arrayPattern(null), $subject)) !== null) {
+			return "empty";
+		}
+		if (($bound = $.match($.arrayPattern($.bind, $.bind), $subject)) !== null) {
+			let [h, t] = $bound;
+			if ($.truthy($.infix(">", h, 0))) {
+				return $.infix("+", "list ", $.call(string, $.call(len, t)));
+			}
+		}
+		if (($bound = $.match($.hashPattern(["name"], $.bind), $subject)) !== null) {
+			let [n] = $bound;
+			return n;
+		}
+		if (($bound = $.match($.literal(null), $subject)) !== null) {
+			return "nothing";
+		}
+		if (($bound = $.match($.wildcard, $subject)) !== null) {
+			return "other";
+		}
+		return $.unmatched($subject);
+	})(x);
+});
+$.call(echo, $.call(describe, 0), " ", $.call(describe, []), " ", $.call(describe, [1, 2]), " ", $.call(describe, new $.Hash([["name", "Al"]])), " ", $.call(describe, null), " ", $.call(describe, [$.prefix("-", 1)]));
+var [first, others] = $.destructure($.arrayPattern($.bind, $.bind), [1, 2, 3], "[first, ...others]");
+var swap = $.fn("fn([a, b]) {\n[b, a]\n}", [{}], function ($arg0) {
+	var [a, b] = $.destructure($.arrayPattern(null, $.bind, $.bind), $arg0, "[a, b]");
+	return [b, a];
+});
+var fullName = $.fn("fn({first:f, last:l}) {\n((f +  ) + l)\n}", [{}], function ($arg0) {
+	var [f, l] = $.destructure($.hashPattern(["first", "last"], $.bind, $.bind), $arg0, "{first:f, last:l}");
+	return $.infix("+", $.infix("+", f, " "), l);
+});
+$.call(echo, first, " ", others, " ", $.call(swap, [1, 2]), " ", $.call(fullName, new $.Hash([["last", "Lee"], ["first", "Al"]])));
+var greet = $.fn("fn(name, greeting = hello, ...marks) {\n(((greeting +  ) + name) + len(marks))\n}", [{name: "name"}, {name: "greeting", default: true}, {rest: true}], function (name, greeting = "hello", marks) {
+	return $.infix("+", $.infix("+", $.infix("+", greeting, " "), name), $.call(len, marks));
+});
+$.call(echo, $.call(greet, "Al"), ", ", $.call(greet, "Bo", "hi", "!", "!"), ", ", $.callNamed(greet, ["greeting", "name"], "yo", "Cy"));
+$.call(echo, $.call(len, $.call(map, $.call(map, [1, 2, 3], addTwo), $.fn("fn(x) {\n(x * 10)\n}", [{name: "x"}], function (x) {
+	return $.infix("*", x, 10);
+}))));
+var new_ = $.fn("fn(x) {\nx\n}", [{name: "x"}], function (x) {
+	return x;
+});
+$.call(echo, $.call(new_, 1));
+var counter = new $.Hash([["count", 2], ["plus", $.fn("fn(self, n) {\n((self.count) + n)\n}", [{name: "self"}, {name: "n"}], function (self, n) {
+	return $.infix("+", $.member(self, "count"), n);
+})]]);
+$.call(echo, $.member(counter, "count"), " ", $.callMethod(counter, "plus", [], 3), " ", $.callMethod("abc", "len", []), " ", $.callMethod($.callMethod([1], "push", [], 2), "tail", []));
+var Point = $.struct("Point", ["x", "y"]);
+var p = $.callNamed(Point, ["y"], 1, 2);
+$.call(echo, p, " ", $.member(p, "y"), " ", $.call(type, p), " ", $.infix("==", p, $.call(Point, 1, 2)), " ", $.infix("!=", p, $.call(Point, 2, 1)));
+var n = 0;
+var n;
+var odds = $.while(() => $.infix("<", n, 10), () => {
+	n = $.infix("+", n, 1);
+	if ($.truthy($.infix("==", $.infix("%", n, 2), 0))) {
+		return $.CONTINUE;
+	}
+	if ($.truthy($.infix(">", n, 7))) {
+		return $.BREAK;
+	}
+	return n;
+});
+var firstBig = $.fn("fn(xs) {\nfor x in xs if (x > 2) return xnull\n}", [{name: "xs"}], function (xs) {
+	try {
+		var x;
+		$.for(xs, ($element) => {
+			x = $element;
+			if ($.truthy($.infix(">", x, 2))) {
+				throw new $.Return(x);
+			}
+			return null;
+		});
+		return null;
+	} catch (e) {
+		return $.caught(e);
+	}
+});
+var k, v, last;
+var pairs = $.for([["a", 1], ["b", 2]], ($element) => {
+	[k, v] = $.destructure($.arrayPattern(null, $.bind, $.bind), $element, "[k, v]");
+	last = k;
+	return $.infix("+", k, $.call(string, v));
+});
+var c;
+var k;
+$.call(echo, odds, " ", n, " ", $.call(firstBig, $.call(range, 5)), " ", pairs, " ", last, " ", $.for("hé", ($element) => {
+	c = $element;
+	return c;
+}), " ", $.for(new $.Hash([["x", 1]]), ($element) => {
+	k = $element;
+	return k;
+}));
+var Line = $.struct("Line", ["points", "tags"]);
+$.call(echo, $.infix("==", $.call(Line, [p], new $.Hash([["a", [1]], ["b", null]])), $.call(Line, [$.call(Point, 1, 2)], new $.Hash([["b", null], ["a", [1]]]))), " ", $.infix("==", $.call(Line, [p], new $.Hash([])), $.call(Line, [p], new $.Hash([["a", 1]]))));
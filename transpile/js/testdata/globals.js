@@ -0,0 +1,19 @@
+var echo = $.builtins.echo;
+var len = $.builtins.len;
+var raw = $.builtins.raw;
+
+var Map = 2;
+var Array = "array";
+var Object = [1];
+var JSON = new $.Hash([["a", 1]]);
+var Math = $.fn("fn(x) {\n(x * 2)\n}", [{name: "x"}], function (x) {
+	return $.infix("*", x, 2);
+});
+var Number = 3;
+var String = null;
+var BigInt = 4;
+var TextEncoder = 5;
+var Error = 6;
+$.call(echo, new $.Hash([["a", 1]]), [Map, Array], Object);
+$.call(echo, JSON, " ", $.call(Math, Number), " ", String, " ", $.infix("|", BigInt, TextEncoder), " ", $.infix("/", Error, 4));
+$.call(echo, $.call(len, "héllo"), " ", $.infix("/", 7, 2), " ", $.call(raw, "x"));
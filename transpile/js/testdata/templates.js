@@ -0,0 +1,13 @@
+var echo = $.builtins.echo;
+var ident = $.builtins.ident;
+var string = $.builtins.string;
+
+var greet = $.fn("fn(name, age) {\n[Hi my name is , name,  and I'm , age]\n}", [{name: "name"}, {name: "age"}], function (name, age) {
+	return `Hi my name is ${$.inspect(name)} and I'm ${$.inspect(age)}`;
+});
+$.call(echo, $.call(greet, "Alice", 25));
+$.call(echo, `Literal {brackets} and \\backslashes`);
+var trap = $.macro("[try { , body,  } catch {}]", ["body"], [string], (body) => `try { ${$.inspect(body)} } catch {}`);
+$.call(echo, $.call(trap, `risky()`));
+var declare = $.macro("[var , name,  = , value]", ["name", "eq", "value"], [ident, "=", string], (name, eq, value) => `var ${$.inspect(name)} = ${$.inspect(value)}`);
+$.call(echo, $.call(declare, `answer = 42`));
@@ -0,0 +1,701 @@
+package js
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"monkey/ast"
+	"monkey/evaluator"
+	"monkey/token"
+	"monkey/transpile"
+	"sort"
+	"strings"
+)
+
+// Implements the Monkey objects, operators and builtins on top of JavaScript
+//
+//go:embed runtime.js
+var RUNTIME string
+
+var BUILTINS = []string{
+	"args", "assert", "echo", "emit", "exit", "eval", "head", "ident", "idents", "int", "last", "len", "push", "range", "raw", "read", "space", "string", "tail", "type",
+}
+
+var reserved = map[string]bool{
+	"arguments": true, "await": true, "break": true, "case": true, "catch": true, "class": true, "const": true,
+	"continue": true, "debugger": true, "default": true, "delete": true, "do": true, "enum": true, "eval": true,
+	"export": true, "extends": true, "finally": true, "for": true, "function": true, "implements": true,
+	"import": true, "in": true, "instanceof": true, "interface": true, "let": true, "new": true, "package": true,
+	"private": true, "protected": true, "public": true, "static": true, "super": true, "switch": true, "this": true,
+	"throw": true, "try": true, "typeof": true, "var": true, "void": true, "while": true, "with": true, "yield": true,
+	"undefined": true, "NaN": true, "Infinity": true, "console": true,
+}
+
+// Where the statement being transpiled lives
+type context struct {
+	inFunction bool
+	// Ifs, matches and loop bodies used as values are arrow functions called in place
+	transpile.Nesting
+	// A return crosses an arrow function so the function must catch it
+	throwsReturn bool
+	// Loops around the statement, their bodies are arrow functions
+	loops int
+	// Bound inside of the loops, declared before them to outlive their bodies
+	hoisted []string
+}
+
+// Names bound by a function, a macro or a match arm
+type scope struct {
+	names map[string]bool
+	outer *scope
+}
+
+// An identifier read in the scope, known once the whole program is transpiled
+type read struct {
+	name  string
+	scope *scope
+}
+
+type Transpiler struct {
+	out    bytes.Buffer
+	indent int
+	ctx    *context
+	scope  *scope
+	used   map[string]bool
+	reads  []read
+	errors []string
+}
+
+func New() *Transpiler {
+	return &Transpiler{ctx: &context{}, scope: &scope{names: map[string]bool{}}, used: map[string]bool{}}
+}
+
+// Returns a standalone script, the runtime followed by the program in a function of its
+// own, so its vars don't replace the globals the runtime uses, like Map or JSON
+func Transpile(program *ast.Program) (string, error) {
+	code, err := New().TranspileProgram(program)
+	if err != nil {
+		return "", err
+	}
+	body := "try {\n" + indentLines(code) + "} catch (e) {\n\t$.report(e);\n}\n"
+	return RUNTIME + "\n(() => {\n" + indentLines(body) + "})();\n", nil
+}
+
+// Returns the program alone, it expects the runtime to be already loaded
+func (t *Transpiler) TranspileProgram(program *ast.Program) (string, error) {
+	for _, stmt := range program.Statements {
+		t.statement(stmt, false)
+	}
+
+	if len(t.errors) != 0 {
+		return "", fmt.Errorf("%s", strings.Join(t.errors, "\n"))
+	}
+
+	var out bytes.Buffer
+	for _, name := range BUILTINS {
+		if t.used[name] {
+			out.WriteString(fmt.Sprintf("var %s = $.builtins.%s;\n", mangle(name), name))
+		}
+	}
+	// Names nothing binds are null like in the interpreter instead of a ReferenceError
+	if unbound := t.unbound(); len(unbound) > 0 {
+		out.WriteString(fmt.Sprintf("var %s = null;\n", strings.Join(unbound, " = null, ")))
+	}
+	if out.Len() > 0 {
+		out.WriteString("\n")
+	}
+	out.Write(t.out.Bytes())
+
+	return out.String(), nil
+}
+
+func (t *Transpiler) errorf(format string, a ...any) string {
+	t.errors = append(t.errors, fmt.Sprintf(format, a...))
+	return "null"
+}
+
+func (t *Transpiler) line(format string, a ...any) {
+	t.out.WriteString(strings.Repeat("\t", t.indent))
+	t.out.WriteString(fmt.Sprintf(format, a...))
+	t.out.WriteString("\n")
+}
+
+// The value of a tail statement is returned, from the function or from the arrow function it is in
+func (t *Transpiler) statement(stmt ast.Statement, tail bool) {
+	switch stmt := stmt.(type) {
+	case *ast.LetStatement:
+		ident, ok := stmt.Name.(*ast.Identifier)
+		if !ok {
+			value := t.expression(stmt.Value)
+			if tail {
+				t.line("var $destructured = %s;", value)
+				value = "$destructured"
+			}
+			t.line("%s%s;", t.declaration(), t.destructure(stmt.Name, value))
+			if tail {
+				t.line("return $destructured;")
+			}
+			return
+		}
+
+		name := t.binding(ident)
+		t.line("%s%s = %s;", t.declaration(), name, t.expression(stmt.Value))
+		t.hoist(name)
+		if tail {
+			t.line("return %s;", name)
+		}
+	case *ast.StructStatement:
+		fields := []string{}
+		for _, f := range stmt.Fields {
+			fields = append(fields, quote(f.Value))
+		}
+		name := t.binding(stmt.Name)
+		t.line("%s%s = $.struct(%s, [%s]);", t.declaration(), name, quote(stmt.Name.Value), strings.Join(fields, ", "))
+		t.hoist(name)
+		if tail {
+			t.line("return %s;", name)
+		}
+	case *ast.BreakStatement:
+		t.loopControl("$.BREAK")
+	case *ast.ContinueStatement:
+		t.loopControl("$.CONTINUE")
+	case *ast.ReturnStatement:
+		t.returnStatement(stmt)
+	case *ast.ExpressionStatement:
+		if exp, ok := stmt.Expression.(*ast.IfExpression); ok {
+			t.ifStatement(exp, tail)
+			return
+		}
+
+		exp := t.expression(stmt.Expression)
+		if tail {
+			t.line("return %s;", exp)
+		} else {
+			t.line("%s;", exp)
+		}
+	default:
+		t.errorf("transpiling %T to JavaScript is not supported", stmt)
+	}
+}
+
+// Throws the break or continue when it must unwind, $.while and $.for catch it
+func (t *Transpiler) loopControl(control string) {
+	if t.ctx.UnwindsLoopControl() {
+		t.line("throw %s;", control)
+	} else {
+		t.line("return %s;", control)
+	}
+}
+
+// Inside of loops the variables are declared before the loop, see hoist
+func (t *Transpiler) declaration() string {
+	if t.ctx.loops > 0 {
+		return ""
+	}
+	return "var "
+}
+
+// Names bound inside of loops are declared before the outermost one, so they
+// are still set after the arrow functions of the bodies like in the evaluator
+func (t *Transpiler) hoist(names ...string) {
+	if t.ctx.loops > 0 {
+		t.ctx.hoisted = append(t.ctx.hoisted, names...)
+	}
+}
+
+// Writes the body of a loop as an arrow function, the first statements bind the element
+func (t *Transpiler) loopBody(params string, bind func(), body *ast.BlockStatement) string {
+	t.ctx.loops++
+
+	code := t.ctx.LoopBody(func() string {
+		return t.nested(func() {
+			t.indent++
+			bind()
+			t.indent--
+			t.block(body, true)
+		})
+	})
+
+	t.ctx.loops--
+
+	if t.ctx.loops == 0 && len(t.ctx.hoisted) > 0 {
+		names := []string{}
+		seen := map[string]bool{}
+		for _, name := range t.ctx.hoisted {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+		t.ctx.hoisted = nil
+		t.line("var %s;", strings.Join(names, ", "))
+	}
+
+	return "(" + params + ") => {\n" + code + strings.Repeat("\t", t.indent) + "}"
+}
+
+func (t *Transpiler) returnStatement(stmt *ast.ReturnStatement) {
+	value := t.expression(stmt.RetValue)
+
+	switch {
+	case !t.ctx.inFunction:
+		t.errorf("return outside of a function can't be transpiled to JavaScript")
+	case t.ctx.UnwindsReturn():
+		t.ctx.throwsReturn = true
+		t.line("throw new $.Return(%s);", value)
+	default:
+		t.line("return %s;", value)
+	}
+}
+
+func (t *Transpiler) block(block *ast.BlockStatement, tail bool) {
+	t.indent++
+	if transpile.Statements(block.Statements, tail, t.statement) {
+		t.line("return null;")
+	}
+	t.indent--
+}
+
+func (t *Transpiler) ifStatement(exp *ast.IfExpression, tail bool) {
+	for i, branch := range exp.Branches {
+		if i == 0 {
+			t.line("if ($.truthy(%s)) {", t.expression(branch.Condition))
+		} else {
+			t.line("} else if ($.truthy(%s)) {", t.expression(branch.Condition))
+		}
+		t.block(branch.Consequence, tail)
+	}
+
+	if exp.Alternative != nil {
+		t.line("} else {")
+		t.block(exp.Alternative, tail)
+		t.line("}")
+		return
+	}
+
+	t.line("}")
+	if tail {
+		t.line("return null;")
+	}
+}
+
+// Returns the JavaScript write adds instead of adding it, for the body of a function
+func (t *Transpiler) nested(write func()) string {
+	out := t.out
+	t.out = bytes.Buffer{}
+
+	write()
+
+	code := t.out.String()
+	t.out = out
+	return code
+}
+
+func (t *Transpiler) expression(exp ast.Expression) string {
+	switch exp := exp.(type) {
+	case *ast.Identifier:
+		return t.identifier(exp)
+	case *ast.IntegerLiteral:
+		return fmt.Sprintf("%d", exp.Value)
+	case *ast.BooleanLiteral:
+		return fmt.Sprintf("%t", exp.Value)
+	case *ast.StringLiteral:
+		return quote(exp.Value)
+	case *ast.TemplateString:
+		return t.template(exp)
+	case *ast.PrefixExpression:
+		return fmt.Sprintf("$.prefix(%s, %s)", quote(exp.Operator), t.expression(exp.Right))
+	case *ast.InfixExpression:
+		switch exp.Operator {
+		case token.AND:
+			return fmt.Sprintf("$.and(%s, () => %s)", t.expression(exp.Left), t.expression(exp.Right))
+		case token.OR:
+			return fmt.Sprintf("$.or(%s, () => %s)", t.expression(exp.Left), t.expression(exp.Right))
+		}
+		return fmt.Sprintf("$.infix(%s, %s, %s)", quote(exp.Operator), t.expression(exp.Left), t.expression(exp.Right))
+	case *ast.IndexExpression:
+		return fmt.Sprintf("$.index(%s, %s)", t.expression(exp.Left), t.expression(exp.Index))
+	case *ast.MemberExpression:
+		return fmt.Sprintf("$.member(%s, %s)", t.expression(exp.Left), quote(exp.Name.Value))
+	case *ast.CallExpression:
+		if ident, ok := exp.Function.(*ast.Identifier); ok && (ident.Value == "quote" || ident.Value == "unquote") {
+			return t.errorf("%s is only available inside of macros expanded before transpiling", ident.Value)
+		}
+		values, named := exp.SplitArguments()
+		names := []string{}
+		for _, name := range named {
+			names = append(names, quote(name))
+		}
+		args := []string{t.expression(exp.Function)}
+		member, method := exp.Function.(*ast.MemberExpression)
+		if method {
+			args = []string{t.expression(member.Left), quote(member.Name.Value)}
+		}
+		if len(names) > 0 || method {
+			args = append(args, "["+strings.Join(names, ", ")+"]")
+		}
+		args = append(args, t.expressions(values)...)
+		if method {
+			return fmt.Sprintf("$.callMethod(%s)", strings.Join(args, ", "))
+		}
+		if len(names) > 0 {
+			return fmt.Sprintf("$.callNamed(%s)", strings.Join(args, ", "))
+		}
+		return fmt.Sprintf("$.call(%s)", strings.Join(args, ", "))
+	case *ast.NamedArgument:
+		return t.errorf("%s is only allowed in calls", exp.String())
+	case *ast.ArrayLiteral:
+		return "[" + strings.Join(t.expressions(exp.Elements), ", ") + "]"
+	case *ast.HashLiteral:
+		return t.hash(exp)
+	case *ast.IfExpression:
+		return t.ifExpression(exp)
+	case *ast.WhileExpression:
+		condition := t.expression(exp.Condition)
+		body := t.loopBody("", func() {}, exp.Body)
+		return fmt.Sprintf("$.while(() => %s, %s)", condition, body)
+	case *ast.ForExpression:
+		iterable := t.expression(exp.Iterable)
+		body := t.loopBody("$element", func() {
+			if ident, ok := exp.Name.(*ast.Identifier); ok {
+				name := t.binding(ident)
+				t.hoist(name)
+				t.line("%s = $element;", name)
+			} else {
+				t.line("%s;", t.destructure(exp.Name, "$element"))
+			}
+		}, exp.Body)
+		return fmt.Sprintf("$.for(%s, %s)", iterable, body)
+	case *ast.MatchExpression:
+		return t.match(exp)
+	case *ast.RestElement:
+		return t.errorf("%s is only allowed in patterns", exp.String())
+	case *ast.DefaultParameter:
+		return t.errorf("%s is only allowed in parameters", exp.String())
+	case *ast.FunctionLiteral:
+		return t.function(exp)
+	case *ast.MacroLiteral:
+		return t.macro(exp)
+	case *ast.AstMacroLiteral:
+		return t.errorf("macro %s must be defined with a top level let to be expanded", exp.String())
+	case nil:
+		return t.errorf("missing expression")
+	}
+	return t.errorf("transpiling %T to JavaScript is not supported", exp)
+}
+
+func (t *Transpiler) expressions(exps []ast.Expression) []string {
+	out := []string{}
+	for _, e := range exps {
+		out = append(out, t.expression(e))
+	}
+	return out
+}
+
+func (t *Transpiler) identifier(ident *ast.Identifier) string {
+	if ident.Value == "null" {
+		return "null"
+	}
+	t.used[ident.Value] = true
+	t.reads = append(t.reads, read{ident.Value, t.scope})
+	return mangle(ident.Value)
+}
+
+// The name bound by a let, a parameter or a pattern
+func (t *Transpiler) binding(ident *ast.Identifier) string {
+	t.scope.names[ident.Value] = true
+	return mangle(ident.Value)
+}
+
+// Functions, macros and match arms bind their names in a scope of their own until it is closed
+func (t *Transpiler) openScope() {
+	t.scope = &scope{names: map[string]bool{}, outer: t.scope}
+}
+
+func (t *Transpiler) closeScope() {
+	t.scope = t.scope.outer
+}
+
+// The mangled names read without being bound in their scope or the outer ones, nor builtins
+func (t *Transpiler) unbound() []string {
+	builtins := map[string]bool{}
+	for _, name := range BUILTINS {
+		builtins[name] = true
+	}
+
+	names := []string{}
+	seen := map[string]bool{}
+	for _, r := range t.reads {
+		bound := builtins[r.name]
+		for s := r.scope; s != nil && !bound; s = s.outer {
+			bound = s.names[r.name]
+		}
+		if !bound && !seen[r.name] {
+			seen[r.name] = true
+			names = append(names, mangle(r.name))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (t *Transpiler) hash(exp *ast.HashLiteral) string {
+	pairs := []string{}
+	for _, pair := range exp.Pairs {
+		pairs = append(pairs, fmt.Sprintf("[%s, %s]", t.expression(pair.Key), t.expression(pair.Value)))
+	}
+
+	return "new $.Hash([" + strings.Join(pairs, ", ") + "])"
+}
+
+func (t *Transpiler) template(exp *ast.TemplateString) string {
+	var out bytes.Buffer
+
+	out.WriteString("`")
+	for _, e := range exp.Elements {
+		switch e := e.(type) {
+		case *ast.StringLiteral:
+			text := strings.ReplaceAll(e.Value, "\\", "\\\\")
+			text = strings.ReplaceAll(text, "`", "\\`")
+			text = strings.ReplaceAll(text, "${", "$\\{")
+			out.WriteString(text)
+		default:
+			out.WriteString("${$.inspect(" + t.expression(e) + ")}")
+		}
+	}
+	out.WriteString("`")
+
+	return out.String()
+}
+
+func (t *Transpiler) ifExpression(exp *ast.IfExpression) string {
+	body := t.ctx.Expression(func() string {
+		return t.nested(func() {
+			t.indent++
+			t.ifStatement(exp, true)
+			t.indent--
+		})
+	})
+
+	return "(() => {\n" + body + strings.Repeat("\t", t.indent) + "})()"
+}
+
+// Each arm binds the names of its pattern with a let in a block of its own
+func (t *Transpiler) match(exp *ast.MatchExpression) string {
+	subject := t.expression(exp.Value)
+
+	body := t.ctx.Expression(func() string {
+		return t.nested(func() {
+			t.indent++
+			t.line("let $bound;")
+			for _, arm := range exp.Arms {
+				t.openScope()
+				names := []string{}
+				t.line("if (($bound = $.match(%s, $subject)) !== null) {", t.pattern(arm.Pattern, &names))
+				t.indent++
+				if len(names) > 0 {
+					t.line("let [%s] = $bound;", strings.Join(names, ", "))
+				}
+				if arm.Guard != nil {
+					t.line("if ($.truthy(%s)) {", t.expression(arm.Guard))
+					t.line("\treturn %s;", t.expression(arm.Value))
+					t.line("}")
+				} else {
+					t.line("return %s;", t.expression(arm.Value))
+				}
+				t.indent--
+				t.line("}")
+				t.closeScope()
+			}
+			t.line("return $.unmatched($subject);")
+			t.indent--
+		})
+	})
+
+	return "(($subject) => {\n" + body + strings.Repeat("\t", t.indent) + "})(" + subject + ")"
+}
+
+// Declaration of the names of the pattern from the value, failing when it doesn't match
+func (t *Transpiler) destructure(pattern ast.Expression, value string) string {
+	names := []string{}
+	p := t.pattern(pattern, &names)
+	t.hoist(names...)
+	return fmt.Sprintf("[%s] = $.destructure(%s, %s, %s)", strings.Join(names, ", "), p, value, quote(pattern.String()))
+}
+
+// Returns a runtime pattern for the pattern, adding the names it binds in order
+func (t *Transpiler) pattern(pattern ast.Expression, names *[]string) string {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		switch pattern.Value {
+		case "_":
+			return "$.wildcard"
+		case "null":
+			return "$.literal(null)"
+		}
+		*names = append(*names, t.binding(pattern))
+		return "$.bind"
+	case *ast.ArrayLiteral:
+		rest := "null"
+		elements := []string{}
+		for _, el := range pattern.Elements {
+			if r, ok := el.(*ast.RestElement); ok {
+				rest = t.pattern(r.Name, names)
+				continue
+			}
+			elements = append(elements, t.pattern(el, names))
+		}
+		return fmt.Sprintf("$.arrayPattern(%s)", strings.Join(append([]string{rest}, elements...), ", "))
+	case *ast.HashLiteral:
+		keys := []string{}
+		values := []string{}
+		for _, pair := range pattern.Pairs {
+			keys = append(keys, t.expression(pair.Key))
+			values = append(values, t.pattern(pair.Value, names))
+		}
+		return fmt.Sprintf("$.hashPattern([%s], %s)", strings.Join(keys, ", "), strings.Join(values, ", "))
+	}
+	return fmt.Sprintf("$.literal(%s)", t.expression(pattern))
+}
+
+func (t *Transpiler) function(exp *ast.FunctionLiteral) string {
+	ctx := t.ctx
+	t.ctx = &context{inFunction: true}
+	t.openScope()
+
+	sources := []string{}
+	signature := []string{}
+	for _, p := range evaluator.Signature(exp.Parameters) {
+		sources = append(sources, p.Source)
+		fields := []string{}
+		if p.Name != "" {
+			fields = append(fields, "name: "+quote(p.Name))
+		}
+		if p.Default {
+			fields = append(fields, "default: true")
+		}
+		if p.Rest {
+			fields = append(fields, "rest: true")
+		}
+		signature = append(signature, "{"+strings.Join(fields, ", ")+"}")
+	}
+
+	// Patterns take their argument in $arg, destructured before the body
+	params := []string{}
+	targets := []ast.Expression{}
+	for i, p := range exp.Parameters {
+		var def ast.Expression
+		switch param := p.(type) {
+		case *ast.DefaultParameter:
+			p, def = param.Parameter, param.Value
+		case *ast.RestElement:
+			p = param.Name
+		}
+
+		param := fmt.Sprintf("$arg%d", i)
+		if ident, ok := p.(*ast.Identifier); ok {
+			param = t.binding(ident)
+		}
+		targets = append(targets, p)
+
+		// Defaults of JavaScript take undefined, which $.call passes for them
+		if def != nil {
+			param += " = " + t.expression(def)
+		}
+		params = append(params, param)
+	}
+
+	body := t.nested(func() {
+		t.indent++
+		for i, p := range targets {
+			if _, ok := p.(*ast.Identifier); !ok {
+				t.line("var %s;", t.destructure(p, fmt.Sprintf("$arg%d", i)))
+			}
+		}
+		t.indent--
+		t.block(exp.Body, true)
+	})
+
+	if t.ctx.throwsReturn {
+		body = t.nested(func() {
+			t.indent++
+			t.line("try {")
+			t.out.WriteString(indentLines(body))
+			t.line("} catch (e) {")
+			t.line("\treturn $.caught(e);")
+			t.line("}")
+			t.indent--
+		})
+	}
+
+	t.ctx = ctx
+	t.closeScope()
+
+	source := fmt.Sprintf("fn(%s) {\n%s\n}", strings.Join(sources, ", "), exp.Body.String())
+	closing := strings.Repeat("\t", t.indent)
+	return fmt.Sprintf("$.fn(%s, [%s], function (%s) {\n%s%s})", quote(source), strings.Join(signature, ", "), strings.Join(params, ", "), body, closing)
+}
+
+func (t *Transpiler) macro(exp *ast.MacroLiteral) string {
+	patterns := t.expressions(exp.Pattern)
+
+	t.openScope()
+	names := []string{}
+	params := []string{}
+	for _, p := range exp.Parameters {
+		names = append(names, quote(p.Value))
+		params = append(params, t.binding(p))
+	}
+	body := t.template(exp.Body)
+	t.closeScope()
+
+	return fmt.Sprintf("$.macro(%s, [%s], [%s], (%s) => %s)",
+		quote(exp.Body.String()), strings.Join(names, ", "), strings.Join(patterns, ", "), strings.Join(params, ", "), body)
+}
+
+func indentLines(code string) string {
+	lines := strings.SplitAfter(code, "\n")
+	for i, l := range lines {
+		if strings.TrimSpace(l) != "" {
+			lines[i] = "\t" + l
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+func mangle(name string) string {
+	if reserved[name] {
+		return name + "_"
+	}
+	return name
+}
+
+// Quotes a string as a JavaScript literal
+func quote(text string) string {
+	var out bytes.Buffer
+
+	out.WriteString(`"`)
+	for _, c := range text {
+		switch c {
+		case '"':
+			out.WriteString(`\"`)
+		case '\\':
+			out.WriteString(`\\`)
+		case '\n':
+			out.WriteString(`\n`)
+		case '\r':
+			out.WriteString(`\r`)
+		case '\t':
+			out.WriteString(`\t`)
+		default:
+			if c < 0x20 || c == 0x2028 || c == 0x2029 {
+				out.WriteString(fmt.Sprintf(`\u%04x`, c))
+			} else {
+				out.WriteRune(c)
+			}
+		}
+	}
+	out.WriteString(`"`)
+
+	return out.String()
+}
@@ -0,0 +1,196 @@
+package js
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"monkey/ast"
+	"monkey/evaluator"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"monkey/transpile/transpiletest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the current output")
+
+func TestGolden(t *testing.T) {
+	files, _ := filepath.Glob("testdata/*.mky")
+
+	for _, file := range files {
+		code, err := New().TranspileProgram(testParseFile(t, file))
+		if err != nil {
+			t.Errorf("could not transpile %s: %s", file, err)
+			continue
+		}
+
+		golden := strings.TrimSuffix(file, ".mky") + ".js"
+		if *update {
+			os.WriteFile(golden, []byte(code), 0644)
+			continue
+		}
+
+		expected, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatalf("could not read %s: %s", golden, err)
+		}
+		if code != string(expected) {
+			t.Errorf("wrong output for %s, run the tests with -update to see the changes. got=\n%s", file, code)
+		}
+	}
+}
+
+func TestRunning(t *testing.T) {
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("node is not installed")
+	}
+
+	files, _ := filepath.Glob("testdata/*.mky")
+
+	for _, file := range files {
+		code, err := Transpile(testParseFile(t, file))
+		if err != nil {
+			t.Errorf("could not transpile %s: %s", file, err)
+			continue
+		}
+
+		expected, err := os.ReadFile(strings.TrimSuffix(file, ".mky") + ".out")
+		if err != nil {
+			t.Fatalf("could not read the output of %s: %s", file, err)
+		}
+
+		cmd := exec.Command(node)
+		cmd.Stdin = strings.NewReader(code)
+		output, _ := cmd.CombinedOutput()
+
+		if string(output) != string(expected) {
+			t.Errorf("wrong output running %s. expected=%q, got=%q", file, expected, output)
+		}
+	}
+}
+
+// Runs the inputs of the evaluator tests compiled to JavaScript comparing them with the evaluator results,
+// each input is the body of a function whose result is printed inspected, or the message of its error
+func TestEvaluatorInputs(t *testing.T) {
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("node is not installed")
+	}
+
+	inputs := transpiletest.EvaluatorInputs(t)
+
+	var code bytes.Buffer
+	code.WriteString(RUNTIME + "\nconst runs = [\n")
+
+	expected := []string{}
+	for _, input := range inputs {
+		program := transpiletest.Parse(t, input)
+		run := &ast.LetStatement{
+			Name:  &ast.Identifier{Value: "$result"},
+			Value: &ast.CallExpression{Function: &ast.FunctionLiteral{Body: &ast.BlockStatement{Statements: program.Statements}}},
+		}
+
+		js, err := New().TranspileProgram(&ast.Program{Statements: []ast.Statement{run}})
+		if err != nil {
+			t.Errorf("could not transpile %q: %s", input, err)
+			continue
+		}
+		code.WriteString("() => {\n" + indentLines(js) + "\treturn $result;\n},\n")
+
+		expected = append(expected, transpiletest.Evaluate(t, input))
+	}
+
+	code.WriteString(`];
+for (const run of runs) {
+	try {
+		console.log(JSON.stringify($.inspect(run())));
+	} catch (e) {
+		console.log(JSON.stringify(e.message));
+	}
+}
+`)
+
+	cmd := exec.Command(node)
+	cmd.Stdin = &code
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("could not run the program: %s\n%s", err, output)
+	}
+
+	results := strings.Split(strings.TrimSuffix(string(output), "\n"), "\n")
+	if len(results) != len(expected) {
+		t.Fatalf("wrong number of results. expected=%d, got=%d", len(expected), len(results))
+	}
+
+	for i, result := range results {
+		var got string
+		if err := json.Unmarshal([]byte(result), &got); err != nil {
+			t.Fatalf("could not read result %q: %s", result, err)
+		}
+		if got != expected[i] {
+			t.Errorf("wrong result for %q. expected=%q, got=%q", inputs[i], expected[i], got)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"return 5", "return outside of a function can't be transpiled to JavaScript"},
+		{"quote(1 + 2)", "quote is only available inside of macros expanded before transpiling"},
+		{"let m = macro(x) { quote(unquote(x)) }; let f = fn() { m }", "macro macro(x) quote(unquote(x)) must be defined with a top level let to be expanded"},
+	}
+
+	for _, tt := range tests {
+		_, err := New().TranspileProgram(parser.New(lexer.New(tt.input)).ParseProgram())
+		if err == nil {
+			t.Errorf("expected error %q for %q", tt.expected, tt.input)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, err.Error())
+		}
+	}
+}
+
+func TestHashKeys(t *testing.T) {
+	code, err := New().TranspileProgram(parser.New(lexer.New(`{"b": 2, "a": 1, 1: true}`)).ParseProgram())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := "new $.Hash([[\"b\", 2], [\"a\", 1], [1, true]]);\n"
+	if code != expected {
+		t.Errorf("wrong hash. expected=%q, got=%q", expected, code)
+	}
+}
+
+func testParseFile(t *testing.T, file string) *ast.Program {
+	text, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("could not read %s: %s", file, err)
+	}
+
+	p := parser.New(lexer.New(string(text)))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("could not parse %s: %v", file, p.Errors())
+	}
+
+	env := object.NewEnvironment()
+	evaluator.DefineMacros(program, env)
+	expanded, expandErr := evaluator.ExpandMacros(program, env)
+	if expandErr != nil {
+		t.Fatalf("could not expand %s: %s", file, expandErr.Inspect())
+	}
+
+	return expanded.(*ast.Program)
+}
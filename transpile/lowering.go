@@ -0,0 +1,60 @@
+// Package transpile decides how the Go and JavaScript transpilers lower what Monkey
+// has and their targets don't, the backends only write the code for each decision
+package transpile
+
+import "monkey/ast"
+
+// Where a statement is compiled. Ifs, matches and loop bodies used as values become
+// functions called in place, a return, a break or a continue inside of them has to
+// unwind to its function or its loop instead of returning from them
+type Nesting struct {
+	// Inside of a value compiled to a function, ended by a return of the Monkey function
+	InExpression bool
+	// Right in the body of a loop, whose function the breaks and continues return from
+	InLoop bool
+}
+
+// Returns the code write compiles for an if or a match used as a value, the loops
+// around it are only reached by unwinding
+func (n *Nesting) Expression(write func() string) string {
+	return n.with(Nesting{InExpression: true}, write)
+}
+
+// Returns the code write compiles for the body of a loop, one iteration of it
+func (n *Nesting) LoopBody(write func() string) string {
+	return n.with(Nesting{InExpression: true, InLoop: true}, write)
+}
+
+// Returns the code write compiles for the body of a function, where a return leaves
+// the function and a loop control has no loop to go to
+func (n *Nesting) Function(write func() string) string {
+	return n.with(Nesting{}, write)
+}
+
+func (n *Nesting) with(inner Nesting, write func() string) string {
+	outer := *n
+	*n = inner
+	defer func() { *n = outer }()
+
+	return write()
+}
+
+// Whether a return has to unwind to the function it ends
+func (n Nesting) UnwindsReturn() bool {
+	return n.InExpression
+}
+
+// Whether a break or a continue has to unwind to its loop
+func (n Nesting) UnwindsLoopControl() bool {
+	return !n.InLoop
+}
+
+// Calls write with each statement and whether it is the tail, the last statement of a
+// block whose value is used, which the backends return from the compiled function.
+// Returns true for a tail block without statements, the backend returns null for it
+func Statements(stmts []ast.Statement, tail bool, write func(stmt ast.Statement, tail bool)) bool {
+	for i, stmt := range stmts {
+		write(stmt, tail && i == len(stmts)-1)
+	}
+	return tail && len(stmts) == 0
+}
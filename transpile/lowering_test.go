@@ -0,0 +1,64 @@
+package transpile
+
+import (
+	"fmt"
+	"monkey/ast"
+	"testing"
+)
+
+func TestNesting(t *testing.T) {
+	var n Nesting
+	unwinds := func() string {
+		return fmt.Sprintf("%t %t", n.UnwindsReturn(), n.UnwindsLoopControl())
+	}
+
+	tests := []struct {
+		write    func(func() string) string
+		expected string
+	}{
+		{func(f func() string) string { return f() }, "false true"},
+		{n.Expression, "true true"},
+		{n.LoopBody, "true false"},
+		{func(f func() string) string {
+			return n.LoopBody(func() string { return n.Expression(f) })
+		}, "true true"},
+		{func(f func() string) string {
+			return n.Expression(func() string { return n.Function(f) })
+		}, "false true"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.write(unwinds); got != tt.expected {
+			t.Errorf("expected %q, got %q", tt.expected, got)
+		}
+		if n != (Nesting{}) {
+			t.Errorf("nesting not restored, got %+v", n)
+		}
+	}
+}
+
+func TestStatements(t *testing.T) {
+	stmts := []ast.Statement{&ast.ExpressionStatement{}, &ast.ExpressionStatement{}}
+
+	tests := []struct {
+		stmts    []ast.Statement
+		tail     bool
+		tails    []bool
+		expected bool
+	}{
+		{stmts, true, []bool{false, true}, false},
+		{stmts, false, []bool{false, false}, false},
+		{nil, true, []bool{}, true},
+		{nil, false, []bool{}, false},
+	}
+
+	for _, tt := range tests {
+		tails := []bool{}
+		empty := Statements(tt.stmts, tt.tail, func(stmt ast.Statement, tail bool) {
+			tails = append(tails, tail)
+		})
+		if empty != tt.expected || fmt.Sprint(tails) != fmt.Sprint(tt.tails) {
+			t.Errorf("expected %v %v, got %v %v", tt.tails, tt.expected, tails, empty)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Package transpiletest has what the tests of the transpilers share to check that
+// the programs they generate give the same results as the evaluator
+package transpiletest
+
+import (
+	goast "go/ast"
+	goparser "go/parser"
+	gotoken "go/token"
+	"monkey/ast"
+	"monkey/evaluator"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+// Gets the Monkey code of the input fields and variables of the evaluator tests
+func EvaluatorInputs(t *testing.T) []string {
+	_, here, _, _ := runtime.Caller(0)
+	tests := filepath.Join(filepath.Dir(here), "..", "..", "evaluator", "evaluator_test.go")
+
+	file, err := goparser.ParseFile(gotoken.NewFileSet(), tests, nil, 0)
+	if err != nil {
+		t.Fatalf("could not parse %s: %s", tests, err)
+	}
+
+	inputs := []string{}
+	add := func(exp goast.Expr) {
+		lit, ok := exp.(*goast.BasicLit)
+		if !ok || lit.Kind != gotoken.STRING {
+			return
+		}
+		if input, err := strconv.Unquote(lit.Value); err == nil {
+			inputs = append(inputs, input)
+		}
+	}
+
+	goast.Inspect(file, func(node goast.Node) bool {
+		switch node := node.(type) {
+		case *goast.AssignStmt:
+			if ident, ok := node.Lhs[0].(*goast.Ident); ok && ident.Name == "input" {
+				add(node.Rhs[0])
+			}
+		case *goast.CompositeLit:
+			if !isInputTable(node) {
+				return true
+			}
+			for _, e := range node.Elts {
+				if row, ok := e.(*goast.CompositeLit); ok && len(row.Elts) > 0 {
+					add(row.Elts[0])
+				}
+			}
+		}
+		return true
+	})
+
+	if len(inputs) == 0 {
+		t.Fatalf("no inputs found in %s", tests)
+	}
+	return inputs
+}
+
+// Whether it is a slice of structs with input as their first field
+func isInputTable(lit *goast.CompositeLit) bool {
+	array, ok := lit.Type.(*goast.ArrayType)
+	if !ok {
+		return false
+	}
+	row, ok := array.Elt.(*goast.StructType)
+	if !ok || len(row.Fields.List) == 0 {
+		return false
+	}
+	names := row.Fields.List[0].Names
+	return len(names) > 0 && names[0].Name == "input"
+}
+
+// Parses the input with its macros expanded, like the commands do before transpiling
+func Parse(t *testing.T, input string) *ast.Program {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+
+	env := object.NewEnvironment()
+	evaluator.DefineMacros(program, env)
+	expanded, err := evaluator.ExpandMacros(program, env)
+	if err != nil {
+		t.Fatalf("could not expand %q: %s", input, err.Inspect())
+	}
+	return expanded.(*ast.Program)
+}
+
+// What the evaluator gives for the input, as the transpiled program must print it
+func Evaluate(t *testing.T, input string) string {
+	result := evaluator.Eval(Parse(t, input), object.NewEnvironment())
+	if result == nil {
+		return "nil"
+	}
+	return result.Inspect()
+}